@@ -0,0 +1,100 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+)
+
+// get the service name
+var notificationsDatabase = sqlx.NewDb(sqldb.Named("notifications").Stdlib(), "postgres")
+
+// defaultSendAt - the time digests are sent when the user did not choose one
+const defaultSendAt = "08:00"
+
+// Get - returns the digest preferences of a user, the defaults when they never set any.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *Preferences
+//	@return error
+func Get(ctx context.Context, uid string) (*Preferences, error) {
+	var preferences Preferences
+	if err := database.NamedStructQuery(ctx, notificationsDatabase, "SELECT * FROM digest_preferences WHERE uid = :uid", map[string]any{
+		"uid": uid,
+	}, &preferences); err != nil {
+		if err == database.ErrNotFound {
+			return &Preferences{UserID: uid, SendAt: defaultSendAt}, nil
+		}
+		return nil, fmt.Errorf("selecting digest preferences: %w", err)
+	}
+
+	return &preferences, nil
+}
+
+// Update - sets the digest preferences of a user, fields missing from the payload are kept.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *UpdatePreferencesPayload
+//	@return *Preferences
+//	@return error
+func Update(ctx context.Context, uid string, payload *UpdatePreferencesPayload) (*Preferences, error) {
+	// query statement to be executed
+	q := `
+    INSERT INTO digest_preferences (uid, enabled, send_at, created_at, updated_at)
+    VALUES (:uid, COALESCE(:enabled, FALSE), COALESCE(NULLIF(:send_at, ''), :default_send_at), :now, :now)
+    ON CONFLICT (uid) DO UPDATE SET
+      enabled = COALESCE(:enabled, digest_preferences.enabled),
+      send_at = COALESCE(NULLIF(:send_at, ''), digest_preferences.send_at),
+      updated_at = :now
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, notificationsDatabase, q, map[string]any{
+		"uid":             uid,
+		"enabled":         payload.Enabled,
+		"send_at":         payload.SendAt,
+		"default_send_at": defaultSendAt,
+		"now":             time.Now().UTC(),
+	}); err != nil {
+		return nil, fmt.Errorf("updating digest preferences: %w", err)
+	}
+
+	return Get(ctx, uid)
+}
+
+// StreamEnabled - passes the preferences of the users who opted in to fn one at a time.
+//
+//	@param ctx - context.Context
+//	@param fn - func(Preferences) error
+//	@return error
+func StreamEnabled(ctx context.Context, fn func(Preferences) error) error {
+	return database.NamedStreamQuery(ctx, notificationsDatabase, "SELECT * FROM digest_preferences WHERE enabled ORDER BY uid", map[string]any{}, fn)
+}
+
+// MarkSent - records the day a user's digest was sent.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param day - YYYY-MM-DD in the user's timezone
+//	@return error
+func MarkSent(ctx context.Context, uid, day string) error {
+	// query statement to be executed
+	q := "UPDATE digest_preferences SET last_sent_on = CAST(:day AS DATE) WHERE uid = :uid"
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, notificationsDatabase, q, map[string]any{
+		"uid": uid,
+		"day": day,
+	}); err != nil {
+		return fmt.Errorf("updating digest preferences: %w", err)
+	}
+
+	return nil
+}
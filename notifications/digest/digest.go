@@ -0,0 +1,113 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"encore.app/notifications/mailer"
+	"encore.app/tasks"
+)
+
+// maxTasks - the most tasks listed in each section of a digest
+const maxTasks = 20
+
+//go:embed templates
+var templates embed.FS
+
+// functions - the functions of the templates
+var functions = map[string]any{
+	"due": func(d *Digest, t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.In(d.Location).Format("Mon, Jan 2 15:04")
+	},
+	"clock": func(d *Digest, t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.In(d.Location).Format("15:04")
+	},
+}
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.New("digest.html").Funcs(functions).ParseFS(templates, "templates/digest.html"))
+	textTemplate = texttemplate.Must(texttemplate.New("digest.txt").Funcs(functions).ParseFS(templates, "templates/digest.txt"))
+)
+
+// Build - collects the tasks of a user's digest: overdue, due today and completed in the last day.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param name - the first name of the user
+//	@param now - the current time in the user's timezone
+//	@return *Digest
+//	@return error
+func Build(ctx context.Context, uid, name string, now time.Time) (*Digest, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	d := &Digest{Name: name, Day: today, Location: now.Location()}
+
+	// the tasks service owns the tasks, the digest asks it for them
+	res, err := tasks.GetUserDigestTasks(ctx, uid, &tasks.DigestTasksPayload{
+		DueFrom:        today,
+		DueTo:          today.AddDate(0, 0, 1),
+		CompletedSince: now.Add(-24 * time.Hour),
+		Limit:          maxTasks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.Overdue, d.DueToday, d.Completed = res.Overdue, res.DueToday, res.Completed
+
+	return d, nil
+}
+
+// Message - renders a digest to an email.
+//
+//	@param d - *Digest
+//	@param to - the address of the user
+//	@return *mailer.Message
+//	@return error
+func (d *Digest) Message(to string) (*mailer.Message, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplate.Execute(&html, d); err != nil {
+		return nil, err
+	}
+	if err := textTemplate.Execute(&text, d); err != nil {
+		return nil, err
+	}
+
+	// summarize the digest in the subject
+	var parts []string
+	if len(d.Overdue) > 0 {
+		parts = append(parts, plural(len(d.Overdue), "overdue task", "overdue tasks"))
+	}
+	if len(d.DueToday) > 0 {
+		parts = append(parts, plural(len(d.DueToday), "task due today", "tasks due today"))
+	}
+	subject := "Your Bookie digest for " + d.Day.Format("Monday, January 2")
+	if len(parts) > 0 {
+		subject += ": " + strings.Join(parts, ", ")
+	}
+
+	return &mailer.Message{To: []string{to}, Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// plural - returns a count with the singular or plural noun.
+//
+//	@param n - int
+//	@param singular - string
+//	@param plural - string
+//	@return string
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%v %v", n, singular)
+	}
+	return fmt.Sprintf("%v %v", n, plural)
+}
@@ -0,0 +1,37 @@
+package digest
+
+import (
+	"time"
+
+	"encore.app/pkg/events"
+)
+
+type Preferences struct {
+	UserID     string     `json:"uid" db:"uid"`
+	Enabled    bool       `json:"enabled" db:"enabled"`         // default: false
+	SendAt     string     `json:"sendAt" db:"send_at"`          // HH:MM in the user's timezone, default: "08:00"
+	LastSentOn *time.Time `json:"lastSentOn" db:"last_sent_on"` // the day of the last digest, in the user's timezone
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type UpdatePreferencesPayload struct {
+	Enabled *bool  `json:"enabled"`                                    // optional
+	SendAt  string `json:"sendAt" validate:"omitempty,datetime=15:04"` // optional, HH:MM in the user's timezone
+}
+
+// Digest - the tasks of a user's daily digest
+type Digest struct {
+	Name      string // the first name of the user
+	Day       time.Time
+	Overdue   []events.Task
+	DueToday  []events.Task
+	Completed []events.Task // completed in the last day
+	Location  *time.Location
+}
+
+// Empty - returns true when the digest has no tasks.
+// @return bool
+func (d *Digest) Empty() bool {
+	return len(d.Overdue)+len(d.DueToday)+len(d.Completed) < 1
+}
@@ -0,0 +1,10 @@
+package mailer
+
+import "errors"
+
+var (
+	// ErrNoRecipients - the message has no recipients
+	ErrNoRecipients = errors.New("mailer: message has no recipients")
+	// ErrInvalidAddress - an address is malformed
+	ErrInvalidAddress = errors.New("mailer: invalid address")
+)
@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File - writes each message to an .eml file in a directory, for local development
+type File struct {
+	Dir  string
+	From string
+}
+
+// NewFile - returns a mailer writing messages to a directory.
+//
+//	@param dir - string
+//	@param from - string
+//	@return *File
+func NewFile(dir, from string) *File {
+	return &File{Dir: dir, From: from}
+}
+
+// Send - writes a message to a new file.
+//
+//	@param ctx - context.Context
+//	@param message - *Message
+//	@return error
+func (f *File) Send(ctx context.Context, message *Message) error {
+	body, err := message.Bytes(f.From)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return fmt.Errorf("creating mail directory: %w", err)
+	}
+
+	// names sort in the order the messages were sent
+	name := fmt.Sprintf("%v-%v.eml", time.Now().UTC().Format("20060102T150405.000000"), uuid.New().String()[:8])
+	if err := os.WriteFile(filepath.Join(f.Dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("writing mail: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package mailer
+
+import "context"
+
+// Mailer - sends emails
+type Mailer interface {
+	// Send - sends a message, the sender is set by the mailer
+	Send(ctx context.Context, message *Message) error
+}
+
+// Message - an email with a plain text body and an optional html body
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string // optional, sent as an alternative to the text
+}
@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory - keeps the messages it is sent, for tests
+type Memory struct {
+	From string
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemory - returns a mailer keeping the messages in memory.
+//
+//	@param from - string
+//	@return *Memory
+func NewMemory(from string) *Memory {
+	return &Memory{From: from}
+}
+
+// Send - keeps a message.
+//
+//	@param ctx - context.Context
+//	@param message - *Message
+//	@return error
+func (m *Memory) Send(ctx context.Context, message *Message) error {
+	// fail the way the other mailers do
+	if _, err := message.Bytes(m.From); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, *message)
+
+	return nil
+}
+
+// Messages - returns the messages sent so far.
+// @return []Message
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message{}, m.messages...)
+}
+
+// Reset - forgets the messages sent so far.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+}
@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Bytes - returns the message as a MIME (RFC 5322) document.
+// With an html body the message is multipart/alternative, the text part first.
+//
+//	@param from - the sender address
+//	@return []byte
+//	@return error
+func (m *Message) Bytes(from string) ([]byte, error) {
+	if len(m.To) < 1 {
+		return nil, ErrNoRecipients
+	}
+
+	// validate the addresses, they are written to the headers as is
+	for _, address := range append([]string{from}, m.To...) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, address)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %v\r\n", from)
+	fmt.Fprintf(&b, "To: %v\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&b, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	// text only
+	if len(m.HTML) < 1 {
+		if err := writePart(&b, "text/plain", m.Text); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	// the preferred alternative comes last
+	for _, part := range []struct{ contentType, body string }{{"text/plain", m.Text}, {"text/html", m.HTML}} {
+		fmt.Fprintf(&b, "--%v\r\n", boundary)
+		if err := writePart(&b, part.contentType, part.body); err != nil {
+			return nil, err
+		}
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%v--\r\n", boundary)
+
+	return b.Bytes(), nil
+}
+
+// writePart - writes the headers and the quoted-printable body of a part.
+//
+//	@param b - *bytes.Buffer
+//	@param contentType - string
+//	@param body - string
+//	@return error
+func writePart(b *bytes.Buffer, contentType, body string) error {
+	fmt.Fprintf(b, "Content-Type: %v; charset=utf-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(b)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// newBoundary - returns a random multipart boundary.
+// @return string
+// @return error
+func newBoundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,62 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+// SMTP - sends emails through an SMTP server, upgrading the connection with STARTTLS when the server supports it
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string // no authentication when empty
+	Password string
+	From     string // the sender address
+}
+
+// NewSMTP - returns a mailer sending through an SMTP server.
+//
+//	@param host - string
+//	@param port - string
+//	@param username - string
+//	@param password - string
+//	@param from - string
+//	@return *SMTP
+func NewSMTP(host, port, username, password, from string) *SMTP {
+	return &SMTP{Host: host, Port: port, Username: username, Password: password, From: from}
+}
+
+// Send - sends a message.
+//
+//	@param ctx - context.Context
+//	@param message - *Message
+//	@return error
+func (s *SMTP) Send(ctx context.Context, message *Message) error {
+	body, err := message.Bytes(s.From)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if len(s.Username) > 0 {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	// smtp.SendMail does not take a context, stop waiting when it is done
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.Host, s.Port), auth, s.From, message.To, body)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sending mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE digest_preferences (
+  uid             UUID NOT NULL PRIMARY KEY,
+  enabled         BOOLEAN NOT NULL DEFAULT FALSE,
+  send_at         VARCHAR(5) NOT NULL DEFAULT '08:00',
+  last_sent_on    DATE DEFAULT NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX digest_preferences_enabled_idx ON digest_preferences (uid) WHERE enabled;
@@ -0,0 +1,238 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"encore.dev"
+	"encore.dev/cron"
+	"encore.dev/rlog"
+	"github.com/go-playground/validator/v10"
+
+	"encore.app/notifications/digest"
+	"encore.app/notifications/mailer"
+	"encore.app/pkg/middleware"
+	"encore.app/users"
+)
+
+// secrets - the SMTP server, emails are written to files when no host is set
+var secrets struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string // e.g. "Bookie <no-reply@bookie.app>"
+}
+
+var (
+	mail     mailer.Mailer
+	mailOnce sync.Once
+)
+
+// getMailer - returns the mailer of the environment: SMTP when configured, in memory for tests
+// and .eml files in the temporary directory otherwise.
+//
+//	@return mailer.Mailer
+func getMailer() mailer.Mailer {
+	mailOnce.Do(func() {
+		from := secrets.MailFrom
+		if len(from) < 1 {
+			from = "Bookie <no-reply@bookie.app>"
+		}
+
+		switch {
+		case len(secrets.SMTPHost) > 0:
+			port := secrets.SMTPPort
+			if len(port) < 1 {
+				port = "587"
+			}
+			mail = mailer.NewSMTP(secrets.SMTPHost, port, secrets.SMTPUsername, secrets.SMTPPassword, from)
+		case encore.Meta().Environment.Type == encore.EnvTest:
+			mail = mailer.NewMemory(from)
+		default:
+			mail = mailer.NewFile(filepath.Join(os.TempDir(), "bookie-mail"), from)
+		}
+	})
+
+	return mail
+}
+
+// authorize - checks that the authenticated user is the user of the path.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return error
+func authorize(ctx context.Context, uid string) error {
+	claims, err := middleware.GetVerifiedClaims(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if claims.Subject.ID != uid {
+		return fmt.Errorf("unauthorized: you are not authorized to perform this action")
+	}
+
+	return nil
+}
+
+// GetDigestPreferences - Get whether a user receives the daily digest and when
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *digest.Preferences
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/notifications/digest
+func GetDigestPreferences(ctx context.Context, uid string) (*digest.Preferences, error) {
+	if err := authorize(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	return digest.Get(ctx, uid)
+}
+
+// UpdateDigestPreferences - Opt in or out of the daily digest and set the time it is sent
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *digest.UpdatePreferencesPayload
+//	@return *digest.Preferences
+//	@return error
+//
+// encore:api auth method=PATCH path=/users/:uid/notifications/digest
+func UpdateDigestPreferences(ctx context.Context, uid string, payload *digest.UpdatePreferencesPayload) (*digest.Preferences, error) {
+	if err := authorize(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return digest.Update(ctx, uid, payload)
+}
+
+// SendDigest - Send a user's digest now, whatever their preferences
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/notifications/digest/send
+func SendDigest(ctx context.Context, uid string) error {
+	if err := authorize(ctx, uid); err != nil {
+		return err
+	}
+
+	user, err := users.GetContact(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	_, err = sendDigest(ctx, user, true)
+	return err
+}
+
+// cron job sending the digests which are due
+var _ = cron.NewJob("send-daily-digests", cron.JobConfig{
+	Title:    "Send the daily digests",
+	Every:    15 * cron.Minute,
+	Endpoint: SendDigests,
+})
+
+// SendDigests - Send the digests of the users whose send time has come today
+// A digest is sent once a day, so runs that were missed are caught up by the next one.
+//
+//	@param ctx - context.Context
+//	@return error
+//
+// encore:api private
+func SendDigests(ctx context.Context) error {
+	// collect the preferences first, sending takes a while
+	var opted []digest.Preferences
+	if err := digest.StreamEnabled(ctx, func(p digest.Preferences) error {
+		opted = append(opted, p)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, p := range opted {
+		if err := sendDue(ctx, p); err != nil {
+			rlog.Error("sending digest failed", "uid", p.UserID, "err", err)
+		}
+	}
+
+	return nil
+}
+
+// sendDue - sends the digest of a user when it is due.
+//
+//	@param ctx - context.Context
+//	@param p - digest.Preferences
+//	@return error
+func sendDue(ctx context.Context, p digest.Preferences) error {
+	user, err := users.GetContact(ctx, p.UserID)
+	if err != nil {
+		return err
+	}
+
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	now := time.Now().In(location)
+	today := now.Format("2006-01-02")
+
+	// once a day, from the send time on
+	if p.LastSentOn != nil && p.LastSentOn.Format("2006-01-02") == today {
+		return nil
+	}
+	if now.Format("15:04") < p.SendAt {
+		return nil
+	}
+
+	// empty digests are not sent
+	if _, err := sendDigest(ctx, user, false); err != nil {
+		return err
+	}
+
+	return digest.MarkSent(ctx, p.UserID, today)
+}
+
+// sendDigest - builds and sends a user's digest.
+//
+//	@param ctx - context.Context
+//	@param user - *users.Contact
+//	@param sendEmpty - send the digest when it has no tasks
+//	@return bool - true when the digest was sent
+//	@return error
+func sendDigest(ctx context.Context, user *users.Contact, sendEmpty bool) (bool, error) {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	d, err := digest.Build(ctx, user.ID, user.Firstname, time.Now().In(location))
+	if err != nil {
+		return false, err
+	}
+	if d.Empty() && !sendEmpty {
+		return false, nil
+	}
+
+	message, err := d.Message(user.Email)
+	if err != nil {
+		return false, err
+	}
+	if err := getMailer().Send(ctx, message); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
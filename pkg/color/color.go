@@ -0,0 +1,66 @@
+package color
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// hexPattern - "#rgb" or "#rrggbb", the "#" is optional
+	hexPattern = regexp.MustCompile(`^#?([0-9a-f]{3}|[0-9a-f]{6})$`)
+	// rgbPattern - "rgb(r, g, b)" with channels from 0 to 255
+	rgbPattern = regexp.MustCompile(`^rgb\(\s*(\d{1,3})\s*,\s*(\d{1,3})\s*,\s*(\d{1,3})\s*\)$`)
+)
+
+// Palette - returns the named colors in the order clients show them.
+//
+//	@return []Color
+func Palette() []Color {
+	return append([]Color{}, palette...)
+}
+
+// Normalize - validates a color and returns its canonical form: the name of a palette color,
+// or "#rrggbb" in lower case for hex and rgb() values.
+//
+//	@param value - string
+//	@return string
+//	@return error
+func Normalize(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	// named colors
+	if name, ok := aliases[value]; ok {
+		value = name
+	}
+	for _, c := range palette {
+		if c.Name == value {
+			return value, nil
+		}
+	}
+
+	// hex values, "#abc" is "#aabbcc"
+	if m := hexPattern.FindStringSubmatch(value); m != nil {
+		hex := m[1]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		return "#" + hex, nil
+	}
+
+	// rgb() values
+	if m := rgbPattern.FindStringSubmatch(value); m != nil {
+		channels := [3]int{}
+		for i := range channels {
+			channel, err := strconv.Atoi(m[i+1])
+			if err != nil || channel > 255 {
+				return "", ErrInvalidColor
+			}
+			channels[i] = channel
+		}
+		return fmt.Sprintf("#%02x%02x%02x", channels[0], channels[1], channels[2]), nil
+	}
+
+	return "", ErrInvalidColor
+}
@@ -0,0 +1,8 @@
+package color
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrInvalidColor - the color is neither a palette color nor a hex or rgb() value
+	ErrInvalidColor = &errs.Error{Code: errs.InvalidArgument, Message: "color must be a palette color, a hex value or rgb(r, g, b)"}
+)
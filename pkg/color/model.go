@@ -0,0 +1,47 @@
+package color
+
+// the named colors of the palette
+const (
+	Default = "default"
+	Red     = "red"
+	Orange  = "orange"
+	Yellow  = "yellow"
+	Green   = "green"
+	Blue    = "blue"
+	Purple  = "purple"
+	Pink    = "pink"
+	Brown   = "brown"
+	Grey    = "grey"
+)
+
+// DefaultCategory - the color of categories created without one
+const DefaultCategory = "#00b3ff"
+
+// Color - a named color of the palette
+type Color struct {
+	Name string `json:"name"`
+	Hex  string `json:"hex"` // the value clients render the color with, "#rrggbb"
+}
+
+type PaletteResponse struct {
+	Colors []Color `json:"data"`
+}
+
+// palette - the named colors in the order clients show them
+var palette = []Color{
+	{Name: Default, Hex: DefaultCategory},
+	{Name: Red, Hex: "#ef4444"},
+	{Name: Orange, Hex: "#f97316"},
+	{Name: Yellow, Hex: "#eab308"},
+	{Name: Green, Hex: "#22c55e"},
+	{Name: Blue, Hex: "#3b82f6"},
+	{Name: Purple, Hex: "#a855f7"},
+	{Name: Pink, Hex: "#ec4899"},
+	{Name: Brown, Hex: "#92400e"},
+	{Name: Grey, Hex: "#6b7280"},
+}
+
+// aliases - other spellings of the named colors
+var aliases = map[string]string{
+	"gray": Grey,
+}
@@ -139,16 +139,16 @@ func queryString(query string, args ...any) string {
 // Most of the time, this will be used for INSERT, UPDATE, and DELETE queries.
 //
 //	@param ctx - context
-//	@param db - database connection
+//	@param db - database connection or transaction
 //	@param query - query to execute
 //	@param data - data to bind to the query
 //	@return error - error if any
-func NamedExecQuery(ctx context.Context, db *sqlx.DB, query string, data any) error {
+func NamedExecQuery(ctx context.Context, db sqlx.ExtContext, query string, data any) error {
 	q := queryString(query, data)
 	rlog.Info("database.NamedExecQuery", "query", q)
 
 	// Execute the query.
-	_, err := db.NamedExecContext(ctx, query, data)
+	_, err := sqlx.NamedExecContext(ctx, db, query, data)
 	if err != nil {
 		return err
 	}
@@ -160,12 +160,12 @@ func NamedExecQuery(ctx context.Context, db *sqlx.DB, query string, data any) er
 // Most of the time, this will be used for SELECT queries.
 //
 //	@param ctx - context
-//	@param db - database connection
+//	@param db - database connection or transaction
 //	@param query - query to execute
 //	@param data - data to bind to the query
 //	@param dest - destination to scan the rows into
 //	@return error - error if any
-func NamedSliceQuery(ctx context.Context, db *sqlx.DB, query string, data any, dest any) error {
+func NamedSliceQuery(ctx context.Context, db sqlx.ExtContext, query string, data any, dest any) error {
 	// get formated query string
 	q := queryString(query, data)
 	// log query info
@@ -179,10 +179,11 @@ func NamedSliceQuery(ctx context.Context, db *sqlx.DB, query string, data any, d
 	}
 
 	// Execute the query.
-	rows, err := db.NamedQueryContext(ctx, query, data)
+	rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
 	// get the next row
 	slice := val.Elem()
@@ -197,27 +198,64 @@ func NamedSliceQuery(ctx context.Context, db *sqlx.DB, query string, data any, d
 		slice.Set(reflect.Append(slice, v.Elem()))
 	}
 
-	return nil
+	return rows.Err()
+}
+
+// NamedStreamQuery - helper function for executing queries whose rows are handled one at a time.
+// Unlike NamedSliceQuery the rows are never loaded into memory together, which suits large results.
+//
+//	@param ctx - context
+//	@param db - database connection or transaction
+//	@param query - query to execute
+//	@param data - data to bind to the query
+//	@param fn - function called with every row, returning an error stops the query
+//	@return error - error if any
+func NamedStreamQuery[T any](ctx context.Context, db sqlx.ExtContext, query string, data any, fn func(T) error) error {
+	q := queryString(query, data)
+	rlog.Info("database.NamedStreamQuery", "query", q)
+
+	// Execute the query.
+	rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// loop through the rows
+	for rows.Next() {
+		var row T
+		// Scan the row into the destination.
+		if err := rows.StructScan(&row); err != nil {
+			return err
+		}
+		// handle the row
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
 // NamedStructQuery - helper function for executing queries that return a single row.
 // Most of the time, this will be used for SELECT queries.
 //
 //	@param ctx - context
-//	@param db - database connection
+//	@param db - database connection or transaction
 //	@param query - query to execute
 //	@param data - data to bind to the query
 //	@param dest - destination to scan the row into
 //	@return error - error if any
-func NamedStructQuery(ctx context.Context, db *sqlx.DB, query string, data any, dest any) error {
+func NamedStructQuery(ctx context.Context, db sqlx.ExtContext, query string, data any, dest any) error {
 	q := queryString(query, data)
 	rlog.Info("database.NamedStructQuery", "query", q)
 
 	// Execute the query.
-	rows, err := db.NamedQueryContext(ctx, query, data)
+	rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
 	// If there are no rows, return an error.
 	if !rows.Next() {
@@ -235,20 +273,21 @@ func NamedStructQuery(ctx context.Context, db *sqlx.DB, query string, data any,
 // NamedCountQuery is a helper function for executing queries that return a count.
 //
 //	@param ctx - context
-//	@param db - database connection
+//	@param db - database connection or transaction
 //	@param query - query to execute
 //	@param data - data to bind to the query
 //	@return int - integer value returned from the query
 //	@return error - error if any
-func NamedCountQuery(ctx context.Context, db *sqlx.DB, query string, data any) (int, error) {
+func NamedCountQuery(ctx context.Context, db sqlx.ExtContext, query string, data any) (int, error) {
 	q := queryString(query, data)
 	rlog.Info("database.NamedQueryCount", "query", q)
 
 	// Execute the query.
-	rows, err := db.NamedQueryContext(ctx, query, data)
+	rows, err := sqlx.NamedQueryContext(ctx, db, query, data)
 	if err != nil {
 		return 0, err
 	}
+	defer rows.Close()
 
 	// If there are no rows, return an error.
 	if !rows.Next() {
@@ -264,3 +303,32 @@ func NamedCountQuery(ctx context.Context, db *sqlx.DB, query string, data any) (
 
 	return count, nil
 }
+
+// Transaction - runs fn in a transaction, committing if it returns no error and rolling back otherwise.
+//
+//	@param ctx - context
+//	@param db - database connection
+//	@param fn - function to run with the transaction
+//	@return error - error if any
+func Transaction(ctx context.Context, db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	// begin the transaction
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	// run the function and roll back on failure
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			rlog.Error("database.Transaction", "rollback", rbErr)
+		}
+		return err
+	}
+
+	// commit the transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package database
 
-import "errors"
+import (
+	"errors"
+
+	"encore.dev/storage/sqldb"
+	"encore.dev/storage/sqldb/sqlerr"
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 // Set of error variables for CRUD operations.
 var (
@@ -9,3 +15,27 @@ var (
 	ErrAuthenticationFailure = errors.New("authentication failed")
 	ErrForbidden             = errors.New("attempted action is not allowed")
 )
+
+// uniqueViolation - the postgres error code of unique constraint violations
+const uniqueViolation = "23505"
+
+// IsUniqueViolation - reports whether err is a violation of the unique constraint or index with the name.
+//
+//	@param err - error
+//	@param constraint - string
+//	@return bool
+func IsUniqueViolation(err error, constraint string) bool {
+	// errors of the standard library connections
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolation && pgErr.ConstraintName == constraint
+	}
+
+	// errors of encore's database api
+	var sqlErr *sqldb.Error
+	if errors.As(err, &sqlErr) {
+		return sqlErr.Code == sqlerr.UniqueViolation && sqlErr.ConstraintName == constraint
+	}
+
+	return false
+}
@@ -6,3 +6,68 @@ import "encore.dev/pubsub"
 var DeleteAllUserTasks = pubsub.NewTopic[*DeleteAllUserTasksEvent]("delete-all-user-tasks", pubsub.TopicConfig{
 	DeliveryGuarantee: pubsub.AtLeastOnce,
 })
+
+// UserCreated - Event published when a user signs up or is created
+var UserCreated = pubsub.NewTopic[*UserCreatedEvent]("user-created", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// StreakMilestone - Event published when a user's completion streak reaches a milestone
+var StreakMilestone = pubsub.NewTopic[*StreakMilestoneEvent]("streak-milestone", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// WebhookDelivery - Event published for every webhook delivery to send, failed deliveries are retried with backoff
+var WebhookDelivery = pubsub.NewTopic[*WebhookDeliveryEvent]("webhook-delivery", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskCreated - Event published when a task is created
+var TaskCreated = pubsub.NewTopic[*TaskCreatedEvent]("task-created", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskUpdated - Event published when fields of a task change
+var TaskUpdated = pubsub.NewTopic[*TaskUpdatedEvent]("task-updated", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskCompleted - Event published when a task is completed
+var TaskCompleted = pubsub.NewTopic[*TaskCompletedEvent]("task-completed", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskUncompleted - Event published when a completed task is marked as not completed
+var TaskUncompleted = pubsub.NewTopic[*TaskUncompletedEvent]("task-uncompleted", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskArchived - Event published when a task is archived
+var TaskArchived = pubsub.NewTopic[*TaskArchivedEvent]("task-archived", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskUnsnoozed - Event published when the snooze of a task ends
+var TaskUnsnoozed = pubsub.NewTopic[*TaskUnsnoozedEvent]("task-unsnoozed", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// TaskDeleted - Event published when a task is deleted
+var TaskDeleted = pubsub.NewTopic[*TaskDeletedEvent]("task-deleted", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// CategoryCreated - Event published when a category is created
+var CategoryCreated = pubsub.NewTopic[*CategoryCreatedEvent]("category-created", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// CategoryUpdated - Event published when fields of a category change
+var CategoryUpdated = pubsub.NewTopic[*CategoryUpdatedEvent]("category-updated", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
+
+// CategoryDeleted - Event published when a category is deleted
+var CategoryDeleted = pubsub.NewTopic[*CategoryDeletedEvent]("category-deleted", pubsub.TopicConfig{
+	DeliveryGuarantee: pubsub.AtLeastOnce,
+})
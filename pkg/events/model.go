@@ -1,6 +1,114 @@
 package events
 
+import "time"
+
 // DeleteAllUserTasksEvent - Delete all user tasks
 type DeleteAllUserTasksEvent struct {
 	UserID string `json:"user_id"`
 }
+
+// UserCreatedEvent - A user was created
+type UserCreatedEvent struct {
+	UserID    string    `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// StreakMilestoneEvent - A user's completion streak reached a milestone
+type StreakMilestoneEvent struct {
+	UserID    string `json:"user_id"`
+	Milestone int    `json:"milestone"`  // the number of days reached, e.g. 7
+	StartedOn string `json:"started_on"` // the first day of the streak, in the user's timezone
+}
+
+// WebhookDeliveryEvent - A logged webhook delivery to send
+type WebhookDeliveryEvent struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// Task - The state of a task in task events
+type Task struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Status       string     `json:"status"`
+	CategoryID   *string    `json:"category_id"` // nil when the task has no category
+	Category     string     `json:"category"`
+	Priority     string     `json:"priority"`
+	Color        string     `json:"color"`
+	Recurrence   string     `json:"recurrence"`
+	Pinned       bool       `json:"pinned"`
+	Archived     bool       `json:"archived"`
+	Completed    bool       `json:"completed"`
+	DueAt        *time.Time `json:"due_at"`        // nil when the task has no due date
+	SnoozedUntil *time.Time `json:"snoozed_until"` // nil when the task is not snoozed
+	CompletedAt  *time.Time `json:"completed_at"`  // nil when the task is not completed
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TaskCreatedEvent - A task was created
+type TaskCreatedEvent struct {
+	Task Task `json:"task"`
+}
+
+// TaskUpdatedEvent - Fields of a task changed
+type TaskUpdatedEvent struct {
+	Task    Task     `json:"task"`    // the task after the change
+	Changed []string `json:"changed"` // the json keys of the changed fields in Task, e.g. "title", "due_at"
+}
+
+// TaskCompletedEvent - A task was completed
+type TaskCompletedEvent struct {
+	Task Task `json:"task"`
+}
+
+// TaskUncompletedEvent - A completed task was marked as not completed
+type TaskUncompletedEvent struct {
+	Task Task `json:"task"`
+}
+
+// TaskArchivedEvent - A task was archived
+type TaskArchivedEvent struct {
+	Task Task `json:"task"`
+}
+
+// TaskUnsnoozedEvent - The snooze of a task ended and it is listed again
+type TaskUnsnoozedEvent struct {
+	Task Task `json:"task"`
+}
+
+// TaskDeletedEvent - A task was deleted
+type TaskDeletedEvent struct {
+	Task Task `json:"task"` // the task before it was deleted
+}
+
+// Category - The state of a category in category events
+type Category struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Color       string    `json:"color"`
+	Icon        string    `json:"icon"`
+	Position    int       `json:"position"`
+	ParentID    *string   `json:"parent_id"` // nil for top level categories
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CategoryCreatedEvent - A category was created
+type CategoryCreatedEvent struct {
+	Category Category `json:"category"`
+}
+
+// CategoryUpdatedEvent - Fields of a category changed
+type CategoryUpdatedEvent struct {
+	Category Category `json:"category"` // the category after the change
+	Changed  []string `json:"changed"`  // the json keys of the changed fields in Category, e.g. "name"
+}
+
+// CategoryDeletedEvent - A category was deleted
+type CategoryDeletedEvent struct {
+	Category Category `json:"category"` // the category before it was deleted
+}
@@ -0,0 +1,179 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar - the data is not a valid iCalendar object
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// Decode - reads the components of an iCalendar object (VTODO, VEVENT...).
+// Nested components such as VALARM are skipped and the VCALENDAR itself is not returned.
+//
+//	@param r - io.Reader
+//	@return []*Component
+//	@return error
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []*Component
+	var current *Component
+	depth := 0
+
+	// loop through the content lines
+	for _, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case p.Name == "BEGIN":
+			depth++
+			// components are direct children of the calendar
+			if depth == 2 {
+				current = &Component{Name: strings.ToUpper(p.Value)}
+			}
+		case p.Name == "END":
+			if depth == 2 && current != nil {
+				components = append(components, current)
+				current = nil
+			}
+			depth--
+		case depth == 2 && current != nil:
+			current.Properties = append(current.Properties, p)
+		}
+	}
+
+	// every component must be closed
+	if depth != 0 {
+		return nil, ErrInvalidCalendar
+	}
+
+	return components, nil
+}
+
+// Get - returns the first property of the component with the name.
+//
+//	@param name - string
+//	@return *Property
+func (c *Component) Get(name string) *Property {
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Text - returns the unescaped TEXT value of the property, empty if the property is nil.
+// @return string
+func (p *Property) Text() string {
+	if p == nil {
+		return ""
+	}
+
+	return strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	).Replace(p.Value)
+}
+
+// Time - returns the DATE or DATE-TIME value of the property.
+// Floating times and times with an unknown TZID are read as UTC.
+// @return time.Time
+// @return error
+func (p *Property) Time() (time.Time, error) {
+	if p == nil {
+		return time.Time{}, ErrInvalidCalendar
+	}
+
+	// use the time zone of the property
+	location := time.UTC
+	if tzid, ok := p.Params["TZID"]; ok {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			location = l
+		}
+	}
+
+	// try the date and date time forms
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		l := location
+		if strings.HasSuffix(layout, "Z") {
+			l = time.UTC
+		}
+		if t, err := time.ParseInLocation(layout, p.Value, l); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	return time.Time{}, ErrInvalidCalendar
+}
+
+// unfold - reads the content lines of r joining folded lines.
+//
+//	@param r - io.Reader
+//	@return []string
+//	@return error
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) < 1 {
+			continue
+		}
+		// a line starting with white space continues the previous line
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseLine - parses a content line into a property (name *(";" param) ":" value).
+//
+//	@param line - string
+//	@return *Property
+//	@return error
+func parseLine(line string) (*Property, error) {
+	p := &Property{}
+
+	// read the name and parameters up to the first colon outside quotes
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			head := strings.Split(line[:i], ";")
+			p.Name = strings.ToUpper(head[0])
+			p.Value = line[i+1:]
+			for _, param := range head[1:] {
+				name, value, _ := strings.Cut(param, "=")
+				if p.Params == nil {
+					p.Params = map[string]string{}
+				}
+				p.Params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+			}
+			return p, nil
+		}
+	}
+
+	return nil, ErrInvalidCalendar
+}
@@ -0,0 +1,185 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineLength - content lines longer than this are folded (RFC 5545 section 3.1)
+const maxLineLength = 75
+
+// Writer - writes an iCalendar stream one component at a time.
+type Writer struct {
+	w *bufio.Writer
+}
+
+// NewWriter - writes the start of a VCALENDAR object to w.
+//
+//	@param w - io.Writer
+//	@param prodID - the identifier of the product creating the calendar
+//	@param name - the display name of the calendar
+//	@return *Writer
+//	@return error
+func NewWriter(w io.Writer, prodID, name string) (*Writer, error) {
+	writer := &Writer{w: bufio.NewWriter(w)}
+
+	// write the calendar properties
+	for _, p := range []*Property{
+		{Name: "BEGIN", Value: "VCALENDAR"},
+		{Name: "VERSION", Value: "2.0"},
+		{Name: "PRODID", Value: prodID},
+		{Name: "CALSCALE", Value: "GREGORIAN"},
+		{Name: "X-WR-CALNAME", Value: Text(name)},
+	} {
+		if err := writer.writeProperty(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return writer, nil
+}
+
+// WriteComponent - writes a component of the calendar.
+//
+//	@param c - *Component
+//	@return error
+func (w *Writer) WriteComponent(c *Component) error {
+	return Encode(w.w, c)
+}
+
+// Close - writes the end of the VCALENDAR object and flushes the writer.
+// @return error
+func (w *Writer) Close() error {
+	if err := w.writeProperty(&Property{Name: "END", Value: "VCALENDAR"}); err != nil {
+		return err
+	}
+
+	return w.w.Flush()
+}
+
+// writeProperty - writes a folded content line.
+//
+//	@param p - *Property
+//	@return error
+func (w *Writer) writeProperty(p *Property) error {
+	_, err := io.WriteString(w.w, p.String())
+	return err
+}
+
+// Encode - writes a component and its properties to w.
+//
+//	@param w - io.Writer
+//	@param c - *Component
+//	@return error
+func Encode(w io.Writer, c *Component) error {
+	lines := []*Property{{Name: "BEGIN", Value: c.Name}}
+	lines = append(lines, c.Properties...)
+	lines = append(lines, &Property{Name: "END", Value: c.Name})
+
+	// write the content lines
+	for _, p := range lines {
+		if _, err := io.WriteString(w, p.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String - returns the folded content line of the property ending with CRLF.
+// @return string
+func (p *Property) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	// write the parameters in a stable order
+	var names []string
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		// quote values containing separators
+		if strings.ContainsAny(value, ":;,") {
+			value = fmt.Sprintf("%q", strings.ReplaceAll(value, `"`, ""))
+		}
+		fmt.Fprintf(&b, ";%v=%v", name, value)
+	}
+
+	b.WriteString(":")
+	b.WriteString(p.Value)
+
+	return fold(b.String())
+}
+
+// fold - splits a content line into lines of at most 75 octets, continuation lines start with a space.
+//
+//	@param line - string
+//	@return string
+func fold(line string) string {
+	var b strings.Builder
+
+	length := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		// never split a character over two lines
+		if length+size > maxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	b.WriteString("\r\n")
+
+	return b.String()
+}
+
+// Text - escapes a TEXT value (RFC 5545 section 3.3.11).
+//
+//	@param s - string
+//	@return string
+func Text(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
+
+// DateTime - formats a DATE-TIME value in UTC.
+//
+//	@param t - time.Time
+//	@return string
+func DateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Add - appends a property to the component.
+//
+//	@param name - string
+//	@param value - the encoded value
+//	@param params - name, value pairs of parameters
+//	@return *Component
+func (c *Component) Add(name, value string, params ...string) *Component {
+	p := &Property{Name: name, Value: value}
+
+	// set the parameters
+	if len(params) > 1 {
+		p.Params = map[string]string{}
+		for i := 0; i+1 < len(params); i += 2 {
+			p.Params[params[i]] = params[i+1]
+		}
+	}
+
+	c.Properties = append(c.Properties, p)
+	return c
+}
@@ -0,0 +1,14 @@
+package ical
+
+// Component - a calendar component such as VTODO or VEVENT
+type Component struct {
+	Name       string      // VTODO, VEVENT
+	Properties []*Property // the properties in order
+}
+
+// Property - a content line of a component
+type Property struct {
+	Name   string            // SUMMARY, DUE
+	Params map[string]string // VALUE=DATE
+	Value  string            // the encoded value
+}
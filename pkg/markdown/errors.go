@@ -0,0 +1,8 @@
+package markdown
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrTaskNotFound - the markdown has no task list item at the index
+	ErrTaskNotFound = &errs.Error{Code: errs.NotFound, Message: "task list item not found"}
+)
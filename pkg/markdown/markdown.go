@@ -0,0 +1,101 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// converter - renders GitHub flavored markdown, raw html is escaped and then removed by the policy
+var converter = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// policy - the html allowed in rendered markdown, user generated content plus task list check boxes
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}()
+
+// Render - renders markdown to html sanitized against XSS.
+//
+//	@param source - string
+//	@return string
+//	@return error
+func Render(source string) (string, error) {
+	if len(strings.TrimSpace(source)) < 1 {
+		return "", nil
+	}
+
+	var b bytes.Buffer
+	if err := converter.Convert([]byte(source), &b); err != nil {
+		return "", err
+	}
+
+	return policy.Sanitize(b.String()), nil
+}
+
+// Tasks - returns the task list items of markdown in document order.
+//
+//	@param source - string
+//	@return []TaskItem
+func Tasks(source string) []TaskItem {
+	src := []byte(source)
+	doc := converter.Parser().Parse(text.NewReader(src))
+
+	items := []TaskItem{}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		checkBox, ok := n.(*extast.TaskCheckBox)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		// the check box is the start of the first line of its text block: "[ ] item"
+		block := checkBox.Parent()
+		if block == nil || block.Lines().Len() < 1 {
+			return ast.WalkContinue, nil
+		}
+
+		items = append(items, TaskItem{
+			Index:   len(items),
+			Offset:  block.Lines().At(0).Start + 1,
+			Checked: checkBox.IsChecked,
+			Text:    strings.TrimSpace(string(block.Text(src))),
+		})
+		return ast.WalkContinue, nil
+	})
+
+	return items
+}
+
+// ToggleTask - checks or unchecks the task list item at the index, the rest of the markdown is unchanged.
+//
+//	@param source - string
+//	@param index - the position of the item in the document, from 0
+//	@return string - the rewritten markdown
+//	@return *TaskItem - the item after the change
+//	@return error
+func ToggleTask(source string, index int) (string, *TaskItem, error) {
+	items := Tasks(source)
+	if index < 0 || index >= len(items) {
+		return "", nil, ErrTaskNotFound
+	}
+
+	item := items[index]
+	mark := " "
+	if !item.Checked {
+		mark = "x"
+	}
+	item.Checked = !item.Checked
+
+	return source[:item.Offset] + mark + source[item.Offset+1:], &item, nil
+}
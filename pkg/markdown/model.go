@@ -0,0 +1,9 @@
+package markdown
+
+// TaskItem - a task list item ("- [ ] item") of a markdown document
+type TaskItem struct {
+	Index   int    `json:"index"`   // the position of the item in the document, from 0
+	Offset  int    `json:"-"`       // the byte offset of the check mark in the source
+	Checked bool   `json:"checked"` // true for "- [x] item"
+	Text    string `json:"text"`
+}
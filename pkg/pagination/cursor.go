@@ -0,0 +1,102 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var secrets struct {
+	PrivateKey string
+}
+
+// NewCursor creates a cursor pointing at an item.
+//
+//	@param key - the sort order the results are listed in
+//	@param values - the values of the sort columns for the item
+//	@param id - the id of the item
+//	@return Cursor
+func NewCursor(key string, values []any, id string) Cursor {
+	c := Cursor{Key: key, Values: []string{}, ID: id}
+
+	// format the sort values so they can be cast back by the database
+	for _, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			c.Values = append(c.Values, v.UTC().Format(time.RFC3339Nano))
+		default:
+			c.Values = append(c.Values, fmt.Sprint(v))
+		}
+	}
+
+	return c
+}
+
+// Encode - encodes the cursor into an opaque signed string.
+//
+//	@return string
+//	@return error
+func (c Cursor) Encode() (string, error) {
+	// marshal the cursor
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	// encode the payload and sign it
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + sign(payload), nil
+}
+
+// DecodeCursor - decodes and verifies a cursor created with Encode.
+//
+//	@param s - the encoded cursor
+//	@param key - the sort order the results are expected to be listed in
+//	@return *Cursor
+//	@return error
+func DecodeCursor(s, key string) (*Cursor, error) {
+	// split the payload from the signature
+	payload, signature, ok := strings.Cut(strings.TrimSpace(s), ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	// verify the signature
+	if !hmac.Equal([]byte(signature), []byte(sign(payload))) {
+		return nil, ErrInvalidCursor
+	}
+
+	// decode the payload
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// a cursor is only valid for the sort order it was created with
+	if c.Key != key || len(c.ID) < 1 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// sign - signs the payload with HMAC-SHA256.
+//
+//	@param payload - string
+//	@return string
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(secrets.PrivateKey))
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
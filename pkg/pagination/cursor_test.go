@@ -0,0 +1,85 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	secrets.PrivateKey = "test-key"
+
+	at := time.Date(2024, time.June, 5, 10, 30, 15, 123456789, time.FixedZone("WAT", 3600))
+
+	tests := []struct {
+		name   string
+		cursor Cursor
+		values []string
+	}{
+		{name: "time", cursor: NewCursor("createdAt:desc", []any{at}, "9b2f"), values: []string{"2024-06-05T09:30:15.123456789Z"}},
+		{name: "several values", cursor: NewCursor("priority:asc,title:asc", []any{2, "Buy milk"}, "9b2f"), values: []string{"2", "Buy milk"}},
+		{name: "no values", cursor: NewCursor("id:asc", nil, "9b2f"), values: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.cursor.Encode()
+			if err != nil {
+				t.Fatalf("Encode() failed: %v", err)
+			}
+
+			decoded, err := DecodeCursor(encoded, tt.cursor.Key)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) failed", encoded)
+			}
+
+			if !reflect.DeepEqual(*decoded, tt.cursor) {
+				t.Errorf("DecodeCursor() = %+v, want %+v", *decoded, tt.cursor)
+			}
+			if !reflect.DeepEqual(decoded.Values, tt.values) {
+				t.Errorf("values = %q, want %q", decoded.Values, tt.values)
+			}
+		})
+	}
+}
+
+func TestCursorTampered(t *testing.T) {
+	secrets.PrivateKey = "test-key"
+
+	encoded, err := NewCursor("createdAt:desc", []any{"2024-06-05T09:30:15Z"}, "9b2f").Encode()
+	if err != nil {
+		t.Fatalf("Encode() failed: %v", err)
+	}
+	payload, signature, _ := strings.Cut(encoded, ".")
+
+	// a payload pointing at another item, signed with another key
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"k":"createdAt:desc","v":["2000-01-01T00:00:00Z"],"id":"0000"}`))
+	secrets.PrivateKey = "another-key"
+	forgedSignature := sign(forged)
+	secrets.PrivateKey = "test-key"
+
+	tests := []struct {
+		name    string
+		encoded string
+		key     string
+	}{
+		{name: "empty", encoded: "", key: "createdAt:desc"},
+		{name: "no signature", encoded: payload, key: "createdAt:desc"},
+		{name: "changed payload", encoded: forged + "." + signature, key: "createdAt:desc"},
+		{name: "changed signature", encoded: payload + "." + strings.ToUpper(signature), key: "createdAt:desc"},
+		{name: "truncated signature", encoded: payload + "." + signature[:len(signature)-1], key: "createdAt:desc"},
+		{name: "signed with another key", encoded: forged + "." + forgedSignature, key: "createdAt:desc"},
+		{name: "other sort order", encoded: encoded, key: "createdAt:asc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// errs.Error needs the encore runtime to be printed, it is compared only
+			if _, err := DecodeCursor(tt.encoded, tt.key); err != ErrInvalidCursor {
+				t.Errorf("DecodeCursor(%q, %q) did not fail with ErrInvalidCursor", tt.encoded, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package pagination
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrInvalidCursor - cursor is malformed, has been tampered with or does not match the sort order
+	ErrInvalidCursor = &errs.Error{Code: errs.InvalidArgument, Message: "invalid cursor"}
+	// ErrInvalidSort - sort field is not sortable or the sort direction is unknown
+	ErrInvalidSort = &errs.Error{Code: errs.InvalidArgument, Message: "invalid sort"}
+	// ErrInvalidFields - a requested field does not exist or may not be selected
+	ErrInvalidFields = &errs.Error{Code: errs.InvalidArgument, Message: "invalid fields"}
+)
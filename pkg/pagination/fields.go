@@ -0,0 +1,117 @@
+package pagination
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"encore.app/pkg/slice"
+)
+
+// Selectable - maps the json names of a struct's fields to their columns.
+//
+//	@param v - the struct to read the tags from
+//	@param exclude - json names of fields that may not be selected
+//	@return map[string]string
+func Selectable(v any, exclude ...string) map[string]string {
+	columns := map[string]string{}
+
+	// loop through the fields and map the json tag to the db tag
+	vt := reflect.Indirect(reflect.ValueOf(v)).Type()
+	for i := 0; i < vt.NumField(); i++ {
+		name := strings.Split(vt.Field(i).Tag.Get("json"), ",")[0]
+		column := vt.Field(i).Tag.Get("db")
+
+		// skip fields that are not stored or not allowed
+		if len(name) < 1 || name == "-" || len(column) < 1 || column == "-" {
+			continue
+		}
+		if slice.Contains(exclude, name) {
+			continue
+		}
+
+		columns[name] = column
+	}
+
+	return columns
+}
+
+// ParseFields - validates the comma separated fields against the selectable columns.
+// A nil fieldset is returned when no fields are requested, meaning all fields.
+//
+//	@param fields - comma separated json names of fields
+//	@param selectable - the json names of fields mapped to their columns
+//	@return *Fieldset
+//	@return error
+func ParseFields(fields string, selectable map[string]string) (*Fieldset, error) {
+	// no fields requested
+	if len(strings.TrimSpace(fields)) < 1 {
+		return nil, nil
+	}
+
+	fieldset := &Fieldset{Fields: []string{}, Columns: []string{}}
+
+	// check the fields against the selectable columns
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		column, ok := selectable[field]
+		if !ok {
+			return nil, ErrInvalidFields
+		}
+		fieldset.Fields = append(fieldset.Fields, field)
+		fieldset.Columns = append(fieldset.Columns, column)
+	}
+
+	return fieldset, nil
+}
+
+// Select returns the select list of the fieldset.
+// Required columns (ids, sort columns) are always selected but are not marshalled unless requested.
+//
+//	@param required - columns the query needs regardless of the fields
+//	@return string
+func (f *Fieldset) Select(required ...string) string {
+	// select everything when no fields are requested
+	if f == nil {
+		return "*"
+	}
+
+	columns := []string{}
+	for _, column := range append(required, f.Columns...) {
+		if !slice.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// Marshal - marshals v keeping only the fields of the fieldset.
+//
+//	@param v - the value to marshal
+//	@return []byte
+//	@return error
+func (f *Fieldset) Marshal(v any) ([]byte, error) {
+	// marshal everything when no fields are requested
+	if f == nil {
+		return json.Marshal(v)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode the value into a map to drop the fields that were not requested
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	for _, field := range f.Fields {
+		fields[field] = all[field]
+	}
+
+	return json.Marshal(fields)
+}
@@ -17,8 +17,37 @@ type Query struct {
 
 // pagination options
 type Options struct {
-	Limit int `json:"limit" db:"limit" url:"limit"` // the number of items
-	Page  int `json:"page" db:"page" url:"limit"`   // the page
+	Limit  int    `json:"limit" db:"limit" url:"limit"`    // the number of items
+	Page   int    `json:"page" db:"page" url:"limit"`      // the page
+	Cursor string `json:"cursor" db:"cursor" url:"cursor"` // the cursor to continue from (keyset pagination)
+	Sort   string `json:"sort" db:"sort" url:"sort"`       // comma separated fields to sort by
+	Order  string `json:"order" db:"order" url:"order"`    // the sort direction: asc, desc
+	Fields string `json:"fields" db:"fields" url:"fields"` // comma separated fields to return
+}
+
+// Cursor - position of the last item of a page in keyset pagination
+type Cursor struct {
+	Key    string   `json:"k"`  // the sort order the results are listed in
+	Values []string `json:"v"`  // the values of the sort columns for the item
+	ID     string   `json:"id"` // the id of the item (tiebreaker)
+}
+
+// Column - a column the results can be sorted by
+type Column struct {
+	Name string // the column name
+	Type string // the SQL type cursor values are cast to
+}
+
+// Fieldset - the validated fields a client asked for
+type Fieldset struct {
+	Fields  []string // the json names of the fields
+	Columns []string // the columns of the fields
+}
+
+// Sorting - the validated sort order of a query
+type Sorting struct {
+	Columns   []Column // the columns to sort by, the id is always appended as a tiebreaker
+	Direction string   // ASC or DESC
 }
 
 // PaginationResponse - pagination
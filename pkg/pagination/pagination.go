@@ -2,6 +2,12 @@ package pagination
 
 import (
 	"math"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20  // the default number of items per page
+	MaxLimit     = 100 // the maximum number of items per page
 )
 
 // New creates a new pagination.
@@ -186,3 +192,9 @@ func (p *Pagination) SetNext(next int) {
 func (p *Pagination) SetOffset(offset int) {
 	p.offset = offset
 }
+
+// UsesCursor returns true if the options request keyset (cursor) pagination.
+// @return bool
+func (o *Options) UsesCursor() bool {
+	return o != nil && len(strings.TrimSpace(o.Cursor)) > 0
+}
@@ -0,0 +1,159 @@
+package pagination
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Sorting - validates the requested sort order against the sortable columns.
+//
+//	@param sortable - the fields clients may sort by mapped to their columns
+//	@param direction - the direction when none is requested, ASC or DESC
+//	@param defaults - the fields to sort by when none are requested
+//	@return *Sorting
+//	@return error
+func (o *Options) Sorting(sortable map[string]Column, direction string, defaults ...string) (*Sorting, error) {
+	// use the defaults if no fields are requested
+	fields := defaults
+	if len(strings.TrimSpace(o.Sort)) > 0 {
+		fields = strings.Split(o.Sort, ",")
+	}
+
+	sorting := &Sorting{Columns: []Column{}, Direction: direction}
+
+	// set the direction
+	switch strings.ToLower(strings.TrimSpace(o.Order)) {
+	case "":
+	case "asc":
+		sorting.Direction = "ASC"
+	case "desc":
+		sorting.Direction = "DESC"
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	// check the fields against the sortable columns
+	for _, field := range fields {
+		column, ok := sortable[strings.TrimSpace(field)]
+		if !ok {
+			return nil, ErrInvalidSort
+		}
+		sorting.Columns = append(sorting.Columns, column)
+	}
+
+	return sorting, nil
+}
+
+// OrderBy returns the ORDER BY expression of the sort order.
+// @return string
+func (s *Sorting) OrderBy() string {
+	var ks []string
+
+	// loop through the columns and append the tiebreaker
+	for _, column := range s.Columns {
+		ks = append(ks, fmt.Sprintf("%v %v", column.Name, s.Direction))
+	}
+	ks = append(ks, fmt.Sprintf("id %v", s.Direction))
+
+	return strings.Join(ks, ", ")
+}
+
+// Names returns the names of the sort columns followed by the id tiebreaker.
+// @return []string
+func (s *Sorting) Names() []string {
+	names := []string{}
+
+	// loop through the columns
+	for _, column := range s.Columns {
+		names = append(names, column.Name)
+	}
+
+	return append(names, "id")
+}
+
+// Key returns the string that identifies the sort order in cursors.
+// @return string
+func (s *Sorting) Key() string {
+	var ks []string
+
+	// loop through the columns
+	for _, column := range s.Columns {
+		ks = append(ks, column.Name)
+	}
+
+	return strings.Join(ks, ",") + ":" + s.Direction
+}
+
+// After returns the condition and arguments that select the items following the cursor.
+// @param cursor - *Cursor
+// @return string
+// @return map[string]any
+// @return error
+func (s *Sorting) After(cursor *Cursor) (string, map[string]any, error) {
+	// the cursor must carry one value per column
+	if len(cursor.Values) != len(s.Columns) {
+		return "", nil, ErrInvalidCursor
+	}
+
+	var columns, values []string
+	args := map[string]any{}
+
+	// loop through the columns and cast the cursor values to their types
+	for i, column := range s.Columns {
+		name := fmt.Sprintf("cursor_%d", i)
+		columns = append(columns, column.Name)
+		values = append(values, fmt.Sprintf("CAST(:%v AS %v)", name, column.Type))
+		args[name] = cursor.Values[i]
+	}
+	columns = append(columns, "id")
+	values = append(values, "CAST(:cursor_id AS UUID)")
+	args["cursor_id"] = cursor.ID
+
+	// compare the rows in the direction of the sort order
+	ops := "<"
+	if s.Direction == "ASC" {
+		ops = ">"
+	}
+
+	return fmt.Sprintf("(%v) %v (%v)", strings.Join(columns, ", "), ops, strings.Join(values, ", ")), args, nil
+}
+
+// NextCursor - creates the cursor pointing at the last item of a page.
+// Items are matched to the sort columns through their db tags.
+//
+//	@param s - *Sorting
+//	@param items - []T
+//	@param hasNext - bool
+//	@return string
+//	@return error
+func NextCursor[T any](s *Sorting, items []T, hasNext bool) (string, error) {
+	// there is nothing to continue from
+	if !hasNext || len(items) < 1 {
+		return "", nil
+	}
+
+	// map the db tags of the last item to their values, skipping unexported and unmapped fields
+	fields := map[string]any{}
+	vp := reflect.Indirect(reflect.ValueOf(items[len(items)-1]))
+	for i := 0; i < vp.NumField(); i++ {
+		field := vp.Type().Field(i)
+		tag := field.Tag.Get("db")
+		if !field.IsExported() || len(tag) < 1 || tag == "-" {
+			continue
+		}
+		fields[tag] = vp.Field(i).Interface()
+	}
+
+	// collect the values of the sort columns
+	var values []any
+	for _, column := range s.Columns {
+		value, ok := fields[column.Name]
+		if !ok {
+			return "", fmt.Errorf("column %v is not a field of %T", column.Name, items[0])
+		}
+		values = append(values, value)
+	}
+
+	return NewCursor(s.Key(), values, fmt.Sprint(fields["id"])).Encode()
+}
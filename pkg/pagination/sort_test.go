@@ -0,0 +1,105 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+)
+
+// item - an item with an unexported field and fields missing from the database, like the models
+type item struct {
+	ID        string    `db:"id"`
+	Title     string    `db:"title"`
+	Due       string    `db:"-"`
+	Note      string    // not a column
+	CreatedAt time.Time `db:"created_at"`
+
+	fieldset *Fieldset
+}
+
+func TestSortingDirection(t *testing.T) {
+	sortable := map[string]Column{"position": {Name: "position", Type: "INTEGER"}, "name": {Name: "name", Type: "TEXT"}}
+
+	tests := []struct {
+		name      string
+		options   Options
+		direction string
+		orderBy   string
+	}{
+		{name: "endpoint default ascending", options: Options{}, direction: "ASC", orderBy: "position ASC, id ASC"},
+		{name: "endpoint default descending", options: Options{}, direction: "DESC", orderBy: "position DESC, id DESC"},
+		{name: "requested order wins", options: Options{Order: "desc"}, direction: "ASC", orderBy: "position DESC, id DESC"},
+		{name: "requested field keeps the default", options: Options{Sort: "name"}, direction: "ASC", orderBy: "name ASC, id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorting, err := tt.options.Sorting(sortable, tt.direction, "position")
+			if err != nil {
+				t.Fatalf("Sorting() failed")
+			}
+			if orderBy := sorting.OrderBy(); orderBy != tt.orderBy {
+				t.Errorf("OrderBy() = %q, want %q", orderBy, tt.orderBy)
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	secrets.PrivateKey = "test-key"
+
+	sorting := &Sorting{Columns: []Column{{Name: "created_at", Type: "TIMESTAMP"}, {Name: "title", Type: "TEXT"}}, Direction: "DESC"}
+	at := time.Date(2024, time.June, 5, 10, 30, 0, 0, time.UTC)
+	items := []item{
+		{ID: "1", Title: "first", CreatedAt: at.Add(time.Hour), fieldset: &Fieldset{}},
+		{ID: "2", Title: "last", CreatedAt: at, fieldset: &Fieldset{}},
+	}
+
+	tests := []struct {
+		name    string
+		items   []item
+		hasNext bool
+		want    *Cursor // nil for no cursor
+	}{
+		{name: "next page", items: items, hasNext: true, want: &Cursor{Key: sorting.Key(), Values: []string{"2024-06-05T10:30:00Z", "last"}, ID: "2"}},
+		{name: "last page", items: items, hasNext: false},
+		{name: "empty page", items: []item{}, hasNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := NextCursor(sorting, tt.items, tt.hasNext)
+			if err != nil {
+				t.Fatalf("NextCursor() failed: %v", err)
+			}
+
+			if tt.want == nil {
+				if len(encoded) > 0 {
+					t.Errorf("NextCursor() = %q, want no cursor", encoded)
+				}
+				return
+			}
+
+			cursor, err := DecodeCursor(encoded, sorting.Key())
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) failed", encoded)
+			}
+			if cursor.Key != tt.want.Key || cursor.ID != tt.want.ID || len(cursor.Values) != len(tt.want.Values) {
+				t.Fatalf("NextCursor() = %+v, want %+v", *cursor, *tt.want)
+			}
+			for i := range cursor.Values {
+				if cursor.Values[i] != tt.want.Values[i] {
+					t.Errorf("value %d = %q, want %q", i, cursor.Values[i], tt.want.Values[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextCursorUnknownColumn(t *testing.T) {
+	sorting := &Sorting{Columns: []Column{{Name: "due", Type: "TEXT"}}, Direction: "ASC"}
+
+	// columns of fields without a db tag cannot be sorted by
+	if _, err := NextCursor(sorting, []item{{ID: "1", Due: "today"}}, true); err == nil {
+		t.Errorf("NextCursor() did not fail for a field without a column")
+	}
+}
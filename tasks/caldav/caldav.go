@@ -0,0 +1,660 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.app/pkg/ical"
+	"encore.app/pkg/middleware"
+	"encore.app/tasks/feed"
+	"encore.app/tasks/ts"
+	"encore.dev/rlog"
+	"github.com/google/uuid"
+)
+
+// maxBodySize - the largest request body accepted
+const maxBodySize = 1 << 20
+
+// calendarPath - returns the path of a calendar.
+//
+//	@param c - *calendar
+//	@return string
+func calendarPath(c *calendar) string {
+	return fmt.Sprintf("%v%v/", homePath, c.ID)
+}
+
+// taskPath - returns the path of a task in a calendar.
+//
+//	@param c - *calendar
+//	@param id - string
+//	@return string
+func taskPath(c *calendar, id string) string {
+	return fmt.Sprintf("%v%v.ics", calendarPath(c), id)
+}
+
+// Serve - handles the CalDAV (RFC 4791) requests of a user.
+// Categories are exposed as calendars and tasks as VTODO resources in them.
+// It returns the task the request saved or deleted, nil when it changed nothing.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@return *Change
+func Serve(w http.ResponseWriter, req *http.Request) *Change {
+	// every request must be authenticated
+	user, err := authenticate(req)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Bookie"`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, maxBodySize)
+
+	var change *Change
+	switch req.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 2, calendar-access")
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		err = propfind(w, req, user)
+	case "REPORT":
+		err = report(w, req, user)
+	case http.MethodGet, http.MethodHead:
+		err = get(w, req, user)
+	case http.MethodPut:
+		change, err = put(w, req, user)
+	case http.MethodDelete:
+		change, err = remove(w, req, user)
+	default:
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+
+	// write the error response
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound), errors.Is(err, ts.ErrNotFound):
+			http.Error(w, ErrNotFound.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrBadRequest), errors.Is(err, ical.ErrInvalidCalendar):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, ErrPreconditionFailed):
+			http.Error(w, err.Error(), http.StatusPreconditionFailed)
+		case errors.Is(err, ErrForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			rlog.Error("caldav request failed", "method", req.Method, "path", req.URL.Path, "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	return change
+}
+
+// target - the resource a path refers to
+type target struct {
+	root      bool
+	principal bool
+	home      bool
+	calendar  *calendar
+	taskID    string // empty when the path is a collection
+}
+
+// resolve - returns the resource the path of a request refers to.
+//
+//	@param ctx - context.Context
+//	@param user - *middleware.User
+//	@param p - the request path
+//	@return *target
+//	@return error
+func resolve(ctx context.Context, user *middleware.User, p string) (*target, error) {
+	rest, ok := strings.CutPrefix(path.Clean("/"+p), strings.TrimSuffix(rootPath, "/"))
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	segments := strings.FieldsFunc(rest, func(r rune) bool { return r == '/' })
+	switch {
+	case len(segments) == 0:
+		return &target{root: true}, nil
+	case segments[0] == "principal" && len(segments) == 1:
+		return &target{principal: true}, nil
+	case segments[0] != "calendars" || len(segments) > 3:
+		return nil, ErrNotFound
+	case len(segments) == 1:
+		return &target{home: true}, nil
+	}
+
+	// find the calendar of the user
+	c, err := findCalendar(ctx, user.ID, segments[1])
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 2 {
+		return &target{calendar: c}, nil
+	}
+
+	// tasks are named after their id
+	name, ok := strings.CutSuffix(segments[2], ".ics")
+	if !ok || len(name) < 1 {
+		return nil, ErrNotFound
+	}
+
+	return &target{calendar: c, taskID: name}, nil
+}
+
+// findTask - returns the task of a user in a calendar.
+//
+//	@param ctx - context.Context
+//	@param user - *middleware.User
+//	@param c - *calendar
+//	@param id - string
+//	@return *ts.Task
+//	@return error
+func findTask(ctx context.Context, user *middleware.User, c *calendar, id string) (*ts.Task, error) {
+	// ids which are not uuids can never match
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	task, err := ts.FindOneByField(ctx, "id", "=", id)
+	if err != nil {
+		return nil, err
+	}
+
+	// the task must belong to the user and calendar
+	if task.UserID != user.ID || !sameCategory(task.CategoryID, c.CategoryID) {
+		return nil, ErrNotFound
+	}
+
+	return &task, nil
+}
+
+// propfind - returns the properties of a resource and, with depth 1, of its members.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param user - *middleware.User
+//	@return error
+func propfind(w http.ResponseWriter, req *http.Request, user *middleware.User) error {
+	ctx := req.Context()
+
+	// an empty body asks for all properties
+	var body propfindRequest
+	if err := decodeBody(req, &body); err != nil {
+		return err
+	}
+
+	t, err := resolve(ctx, user, req.URL.Path)
+	if err != nil {
+		return err
+	}
+
+	// infinite depth is not supported, it is treated as depth 1
+	depth := req.Header.Get("Depth")
+	children := depth != "0"
+
+	var resources []*resource
+	switch {
+	case t.root:
+		resources = append(resources, rootResource(user))
+		if children {
+			resources = append(resources, principalResource(user), homeResource(user))
+		}
+	case t.principal:
+		resources = append(resources, principalResource(user))
+	case t.home:
+		resources = append(resources, homeResource(user))
+		if children {
+			list, err := calendars(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			for i := range list {
+				r, err := calendarResource(ctx, user, &list[i])
+				if err != nil {
+					return err
+				}
+				resources = append(resources, r)
+			}
+		}
+	case len(t.taskID) > 0:
+		task, err := findTask(ctx, user, t.calendar, t.taskID)
+		if err != nil {
+			return err
+		}
+		r, err := taskResource(t.calendar, *task)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, r)
+	default:
+		r, err := calendarResource(ctx, user, t.calendar)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, r)
+		if children {
+			if err := ts.StreamUserTasksInCategory(ctx, user.ID, t.calendar.CategoryID, func(task ts.Task) error {
+				r, err := taskResource(t.calendar, task)
+				if err != nil {
+					return err
+				}
+				resources = append(resources, r)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the properties to return
+	var names []xml.Name
+	all := body.AllProp != nil || (body.PropName == nil && len(body.Prop.Names) == 0)
+	for _, n := range body.Prop.Names {
+		names = append(names, n.XMLName)
+	}
+
+	ms := multistatus{}
+	for _, r := range resources {
+		// calendar data is only returned when requested
+		if all {
+			delete(r.Props, propCalendarData)
+		}
+		ms.Responses = append(ms.Responses, r.response(names, all, body.PropName != nil))
+	}
+
+	return writeMultistatus(w, ms)
+}
+
+// report - handles the calendar-query and calendar-multiget reports of a calendar.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param user - *middleware.User
+//	@return error
+func report(w http.ResponseWriter, req *http.Request, user *middleware.User) error {
+	ctx := req.Context()
+
+	var body reportRequest
+	if err := decodeBody(req, &body); err != nil {
+		return err
+	}
+
+	// the properties to return
+	var names []xml.Name
+	for _, n := range body.Prop.Names {
+		names = append(names, n.XMLName)
+	}
+
+	ms := multistatus{}
+	switch body.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		t, err := resolve(ctx, user, req.URL.Path)
+		if err != nil {
+			return err
+		}
+		if t.calendar == nil || len(t.taskID) > 0 {
+			return ErrBadRequest
+		}
+
+		// every task of the calendar matches, clients filter the VTODOs themselves
+		if err := ts.StreamUserTasksInCategory(ctx, user.ID, t.calendar.CategoryID, func(task ts.Task) error {
+			r, err := taskResource(t.calendar, task)
+			if err != nil {
+				return err
+			}
+			ms.Responses = append(ms.Responses, r.response(names, len(names) == 0, false))
+			return nil
+		}); err != nil {
+			return err
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range body.Hrefs {
+			// hrefs may be absolute urls
+			p := href
+			if i := strings.Index(p, rootPath); i > 0 {
+				p = p[i:]
+			}
+
+			r, err := multigetResource(ctx, user, p)
+			if errors.Is(err, ErrNotFound) || errors.Is(err, ts.ErrNotFound) {
+				ms.Responses = append(ms.Responses, response{Href: href, Status: status(http.StatusNotFound)})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			ms.Responses = append(ms.Responses, r.response(names, len(names) == 0, false))
+		}
+	default:
+		return ErrBadRequest
+	}
+
+	return writeMultistatus(w, ms)
+}
+
+// multigetResource - returns the task resource of a path.
+//
+//	@param ctx - context.Context
+//	@param user - *middleware.User
+//	@param p - string
+//	@return *resource
+//	@return error
+func multigetResource(ctx context.Context, user *middleware.User, p string) (*resource, error) {
+	t, err := resolve(ctx, user, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.taskID) < 1 {
+		return nil, ErrNotFound
+	}
+
+	task, err := findTask(ctx, user, t.calendar, t.taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskResource(t.calendar, *task)
+}
+
+// get - returns a task, or every task of a calendar, as an iCalendar object.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param user - *middleware.User
+//	@return error
+func get(w http.ResponseWriter, req *http.Request, user *middleware.User) error {
+	ctx := req.Context()
+
+	t, err := resolve(ctx, user, req.URL.Path)
+	if err != nil {
+		return err
+	}
+	if t.calendar == nil {
+		return ErrNotFound
+	}
+
+	var b bytes.Buffer
+	writer, err := ical.NewWriter(&b, prodID, t.calendar.Name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(t.taskID) > 0 {
+		task, err := findTask(ctx, user, t.calendar, t.taskID)
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteComponent(feed.Todo(*task, now)); err != nil {
+			return err
+		}
+		w.Header().Set("ETag", etag(*task))
+		w.Header().Set("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+	} else {
+		if err := ts.StreamUserTasksInCategory(ctx, user.ID, t.calendar.CategoryID, func(task ts.Task) error {
+			return writer.WriteComponent(feed.Todo(task, now))
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if req.Method != http.MethodHead {
+		_, err = w.Write(b.Bytes())
+	}
+
+	return err
+}
+
+// put - creates or replaces a task from the VTODO of the request body.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param user - *middleware.User
+//	@return *Change
+//	@return error
+func put(w http.ResponseWriter, req *http.Request, user *middleware.User) (*Change, error) {
+	ctx := req.Context()
+
+	t, err := resolve(ctx, user, req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.taskID) < 1 {
+		return nil, ErrForbidden
+	}
+
+	// get the todo from the body
+	components, err := ical.Decode(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var todo *ical.Component
+	for _, c := range components {
+		if c.Name == "VTODO" {
+			todo = c
+			break
+		}
+	}
+	if todo == nil {
+		return nil, ErrBadRequest
+	}
+
+	// clients choose the resource names, names that are not ids are mapped to a stable id
+	id := t.taskID
+	if _, err := uuid.Parse(id); err != nil {
+		id = uuid.NewSHA1(uuid.NameSpaceURL, []byte(user.ID+"/"+t.taskID)).String()
+	}
+
+	// get the existing task
+	existing, err := ts.FindOneByField(ctx, "id", "=", id)
+	if err != nil && !errors.Is(err, ts.ErrNotFound) {
+		return nil, err
+	}
+	exists := err == nil
+	if exists && existing.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+
+	// check the preconditions of the client
+	if match := req.Header.Get("If-Match"); len(match) > 0 && (!exists || (match != "*" && match != etag(existing))) {
+		return nil, ErrPreconditionFailed
+	}
+	if req.Header.Get("If-None-Match") == "*" && exists {
+		return nil, ErrPreconditionFailed
+	}
+
+	now := time.Now().UTC()
+	task := existing
+	if !exists {
+		task = ts.Task{
+			ID:         id,
+			UserID:     user.ID,
+			Color:      "default",
+			PinnedAt:   now,
+			ArchivedAt: now,
+			CreatedAt:  now,
+		}
+	}
+
+	// set the fields of the todo
+	if err := apply(&task, todo, now); err != nil {
+		return nil, err
+	}
+	task.CategoryID, task.Category = t.calendar.CategoryID, ""
+	task.UpdatedAt = now
+
+	change := &Change{Task: &task}
+	if exists {
+		change.Previous = &existing
+		err = ts.Replace(ctx, tasksDatabase, &task)
+	} else {
+		err = ts.Insert(ctx, tasksDatabase, &task)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("ETag", etag(task))
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.Header().Set("Location", taskPath(t.calendar, task.ID))
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	return change, nil
+}
+
+// apply - sets the fields of a task from a VTODO.
+//
+//	@param task - *ts.Task
+//	@param todo - *ical.Component
+//	@param now - time.Time
+//	@return error
+func apply(task *ts.Task, todo *ical.Component, now time.Time) error {
+	task.Title = strings.TrimSpace(todo.Get("SUMMARY").Text())
+	if len(task.Title) < 1 {
+		task.Title = "Untitled"
+	}
+	task.Description = todo.Get("DESCRIPTION").Text()
+
+	// the due date is optional
+	task.DueAt = nil
+	if due := todo.Get("DUE"); due != nil {
+		dueAt, err := due.Time()
+		if err != nil {
+			return err
+		}
+		task.DueAt = &dueAt
+	}
+
+	// repeating tasks
+	task.Recurrence = todo.Get("RRULE").Text()
+
+	// PRIORITY 0 is undefined, 1-4 high, 5 medium and 6-9 low
+	task.Priority = ts.PriorityNone
+	if p, err := strconv.Atoi(todo.Get("PRIORITY").Text()); err == nil {
+		switch {
+		case p >= 1 && p < feed.Priorities[ts.PriorityMedium]:
+			task.Priority = ts.PriorityHigh
+		case p == feed.Priorities[ts.PriorityMedium]:
+			task.Priority = ts.PriorityMedium
+		case p > feed.Priorities[ts.PriorityMedium] && p <= feed.Priorities[ts.PriorityLow]:
+			task.Priority = ts.PriorityLow
+		}
+	}
+
+	// completion
+	completed := strings.EqualFold(todo.Get("STATUS").Text(), "COMPLETED") || todo.Get("COMPLETED") != nil
+	if completed && !task.Completed {
+		task.CompletedAt = now
+		if at, err := todo.Get("COMPLETED").Time(); err == nil {
+			task.CompletedAt = at
+		}
+	}
+	task.Completed = completed
+
+	// keep archived tasks archived
+	switch {
+	case task.Archived:
+		task.Status = "archived"
+	case task.Completed:
+		task.Status = "completed"
+	default:
+		task.Status = "pending"
+	}
+
+	return nil
+}
+
+// remove - deletes a task.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param user - *middleware.User
+//	@return *Change
+//	@return error
+func remove(w http.ResponseWriter, req *http.Request, user *middleware.User) (*Change, error) {
+	ctx := req.Context()
+
+	t, err := resolve(ctx, user, req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	// collections are managed through the categories api
+	if len(t.taskID) < 1 {
+		return nil, ErrForbidden
+	}
+
+	task, err := findTask(ctx, user, t.calendar, t.taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the precondition of the client
+	if match := req.Header.Get("If-Match"); len(match) > 0 && match != "*" && match != etag(*task) {
+		return nil, ErrPreconditionFailed
+	}
+
+	if err := ts.Delete(ctx, task.ID); err != nil {
+		return nil, err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return &Change{Previous: task}, nil
+}
+
+// decodeBody - decodes the xml body of a request, an empty body is left as the zero value.
+//
+//	@param req - *http.Request
+//	@param v - any
+//	@return error
+func decodeBody(req *http.Request, v any) error {
+	if err := xml.NewDecoder(req.Body).Decode(v); err != nil && err != io.EOF {
+		return ErrBadRequest
+	}
+	return nil
+}
+
+// writeMultistatus - writes a 207 Multi-Status response.
+//
+//	@param w - http.ResponseWriter
+//	@param ms - multistatus
+//	@return error
+func writeMultistatus(w http.ResponseWriter, ms multistatus) error {
+	body, err := xml.Marshal(ms)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	return err
+}
+
+// status - returns the status line of a code.
+//
+//	@param code - int
+//	@return string
+func status(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %v %v", code, http.StatusText(code))
+}
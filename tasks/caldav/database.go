@@ -0,0 +1,117 @@
+package caldav
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"encore.app/pkg/middleware"
+	"encore.app/pkg/pagination"
+	"encore.app/tasks/cs"
+	us "encore.app/users/store"
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+)
+
+// tasksDatabase - the database tasks are synced to
+var tasksDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// defaultCategory - tasks without a category are listed in this calendar
+const defaultCategory = "general"
+
+// authenticate - returns the user of the request's basic credentials (email, password) or bearer token.
+// CalDAV clients only support basic authentication, so the credentials are checked here.
+//
+//	@param req - *http.Request
+//	@return *middleware.User
+//	@return error
+func authenticate(req *http.Request) (*middleware.User, error) {
+	// accept the api tokens
+	if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok {
+		claims, err := middleware.ValidateToken(strings.TrimSpace(token))
+		if err != nil {
+			return nil, ErrUnauthorized
+		}
+		return claims.User, nil
+	}
+
+	// get the user details from the request
+	email, password, ok := req.BasicAuth()
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	// get the user
+	user, err := us.Get(req.Context(), email)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	// check if the password is correct
+	if isCorrect, err := middleware.ComparePasswords(user.Password, password); err != nil || !isCorrect {
+		return nil, ErrUnauthorized
+	}
+
+	return &middleware.User{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+	}, nil
+}
+
+// calendars - returns the calendars of a user, one per category.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return []calendar
+//	@return error
+func calendars(ctx context.Context, uid string) ([]calendar, error) {
+	// tasks are created without a category when none is given
+	list := []calendar{{ID: defaultCategory, Name: defaultCategory}}
+
+	// loop through the categories
+	if err := cs.StreamUserCategories(ctx, uid, &pagination.Options{Sort: "name", Order: "asc"}, func(category cs.Category) error {
+		id := category.ID
+		list = append(list, calendar{ID: category.ID, Name: category.Name, CategoryID: &id, Description: category.Description})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// findCalendar - returns the calendar of a user with the id.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return *calendar
+//	@return error
+func findCalendar(ctx context.Context, uid, id string) (*calendar, error) {
+	list, err := calendars(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range list {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// sameCategory - reports whether two category ids are both unset or equal.
+//
+//	@param a - *string
+//	@param b - *string
+//	@return bool
+func sameCategory(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,16 @@
+package caldav
+
+import "errors"
+
+var (
+	// ErrUnauthorized - the request has no valid credentials
+	ErrUnauthorized = errors.New("caldav: invalid credentials")
+	// ErrNotFound - the path does not name a resource of the user
+	ErrNotFound = errors.New("caldav: resource not found")
+	// ErrBadRequest - the request body is malformed
+	ErrBadRequest = errors.New("caldav: malformed request")
+	// ErrForbidden - the method is not allowed on the resource
+	ErrForbidden = errors.New("caldav: operation not allowed on resource")
+	// ErrPreconditionFailed - the If-Match or If-None-Match header did not match
+	ErrPreconditionFailed = errors.New("caldav: precondition failed")
+)
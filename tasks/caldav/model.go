@@ -0,0 +1,95 @@
+package caldav
+
+import (
+	"encoding/xml"
+
+	"encore.app/tasks/ts"
+)
+
+// prodID - identifies bookie as the creator of the calendars
+const prodID = "-//Bookie//Bookie API//EN"
+
+// paths of the collections
+const (
+	rootPath      = "/dav/"
+	principalPath = "/dav/principal/"
+	homePath      = "/dav/calendars/"
+)
+
+// xml namespaces
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+// calendar - a category exposed as a calendar collection
+type calendar struct {
+	ID          string  // the path segment of the calendar
+	Name        string  // the name of the category
+	CategoryID  *string // the category of the tasks, nil for the tasks without a category
+	Description string
+}
+
+// Change - a task saved or deleted by a CalDAV request, the service publishes its events
+type Change struct {
+	Previous *ts.Task // the task before the request, nil when it was created
+	Task     *ts.Task // the task after the request, nil when it was deleted
+}
+
+// resource - a DAV resource and the inner xml of its properties
+type resource struct {
+	Href  string
+	Props map[xml.Name]string
+}
+
+// element - an xml element identified by its name only
+type element struct {
+	XMLName xml.Name
+}
+
+// propfindRequest - the body of a PROPFIND request
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     struct {
+		Names []element `xml:",any"`
+	} `xml:"DAV: prop"`
+}
+
+// reportRequest - the body of a calendar-query or calendar-multiget REPORT
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    struct {
+		Names []element `xml:",any"`
+	} `xml:"DAV: prop"`
+	Hrefs []string `xml:"DAV: href"`
+}
+
+// multistatus - the body of a 207 Multi-Status response
+type multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []response `xml:"response"`
+}
+
+type response struct {
+	Href      string     `xml:"href"`
+	Propstats []propstat `xml:"propstat,omitempty"`
+	Status    string     `xml:"status,omitempty"`
+}
+
+type propstat struct {
+	Prop   prop   `xml:"prop"`
+	Status string `xml:"status"`
+}
+
+type prop struct {
+	Values []property
+}
+
+// property - a property element with raw inner xml
+type property struct {
+	XMLName  xml.Name
+	InnerXML string `xml:",innerxml"`
+}
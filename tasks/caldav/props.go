@@ -0,0 +1,226 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"time"
+
+	"encore.app/pkg/ical"
+	"encore.app/pkg/middleware"
+	"encore.app/tasks/feed"
+	"encore.app/tasks/ts"
+)
+
+// names of the properties
+var (
+	propResourceType         = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName          = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag              = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType       = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propGetLastModified      = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propCurrentUserPrincipal = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL         = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner                = xml.Name{Space: nsDAV, Local: "owner"}
+	propSupportedReportSet   = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propPrivilegeSet         = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propCalendarHomeSet      = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarDescription  = xml.Name{Space: nsCalDAV, Local: "calendar-description"}
+	propSupportedComponents  = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData         = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propGetCTag              = xml.Name{Space: nsCS, Local: "getctag"}
+)
+
+// href - returns the inner xml of a property holding an href.
+//
+//	@param path - string
+//	@return string
+func href(path string) string {
+	// the parent may be in another namespace
+	return fmt.Sprintf(`<href xmlns="%v">%v</href>`, nsDAV, text(path))
+}
+
+// text - escapes character data.
+//
+//	@param s - string
+//	@return string
+func text(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// principalProps - the properties every collection shares.
+//
+//	@return map[xml.Name]string
+func principalProps() map[xml.Name]string {
+	return map[xml.Name]string{
+		propCurrentUserPrincipal: href(principalPath),
+		propOwner:                href(principalPath),
+		propPrivilegeSet:         "<privilege><read/></privilege><privilege><write/></privilege><privilege><write-content/></privilege><privilege><bind/></privilege><privilege><unbind/></privilege>",
+	}
+}
+
+// rootResource - the entry point of the server.
+//
+//	@param user - *middleware.User
+//	@return *resource
+func rootResource(user *middleware.User) *resource {
+	props := principalProps()
+	props[propResourceType] = "<collection/>"
+	props[propDisplayName] = "Bookie"
+
+	return &resource{Href: rootPath, Props: props}
+}
+
+// principalResource - the principal of the user.
+//
+//	@param user - *middleware.User
+//	@return *resource
+func principalResource(user *middleware.User) *resource {
+	props := principalProps()
+	props[propResourceType] = "<collection/><principal/>"
+	props[propDisplayName] = text(user.Username)
+	props[propPrincipalURL] = href(principalPath)
+	props[propCalendarHomeSet] = href(homePath)
+
+	return &resource{Href: principalPath, Props: props}
+}
+
+// homeResource - the collection holding the calendars of the user.
+//
+//	@param user - *middleware.User
+//	@return *resource
+func homeResource(user *middleware.User) *resource {
+	props := principalProps()
+	props[propResourceType] = "<collection/>"
+	props[propDisplayName] = "Calendars"
+
+	return &resource{Href: homePath, Props: props}
+}
+
+// calendarResource - a category as a calendar of VTODOs.
+//
+//	@param ctx - context.Context
+//	@param user - *middleware.User
+//	@param c - *calendar
+//	@return *resource
+//	@return error
+func calendarResource(ctx context.Context, user *middleware.User, c *calendar) (*resource, error) {
+	// the ctag changes whenever a task of the calendar changes
+	version, err := ts.CategoryVersion(ctx, user.ID, c.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	props := principalProps()
+	props[propResourceType] = fmt.Sprintf(`<collection/><calendar xmlns="%v"/>`, nsCalDAV)
+	props[propDisplayName] = text(c.Name)
+	props[propCalendarDescription] = text(c.Description)
+	props[propSupportedComponents] = fmt.Sprintf(`<comp xmlns="%v" name="VTODO"/>`, nsCalDAV)
+	props[propSupportedReportSet] = fmt.Sprintf(
+		`<supported-report><report><calendar-query xmlns="%v"/></report></supported-report><supported-report><report><calendar-multiget xmlns="%v"/></report></supported-report>`,
+		nsCalDAV, nsCalDAV,
+	)
+	props[propGetCTag] = text(version)
+	props[propGetETag] = text(fmt.Sprintf("%q", version))
+
+	return &resource{Href: calendarPath(c), Props: props}, nil
+}
+
+// taskResource - a task as a VTODO resource of a calendar.
+//
+//	@param c - *calendar
+//	@param task - ts.Task
+//	@return *resource
+//	@return error
+func taskResource(c *calendar, task ts.Task) (*resource, error) {
+	data, err := calendarData(task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resource{
+		Href: taskPath(c, task.ID),
+		Props: map[xml.Name]string{
+			propResourceType:    "",
+			propGetETag:         text(etag(task)),
+			propGetContentType:  "text/calendar; charset=utf-8; component=vtodo",
+			propGetLastModified: task.UpdatedAt.UTC().Format(http.TimeFormat),
+			propCalendarData:    text(data),
+		},
+	}, nil
+}
+
+// calendarData - returns a task as an iCalendar object holding one VTODO.
+//
+//	@param task - ts.Task
+//	@return string
+//	@return error
+func calendarData(task ts.Task) (string, error) {
+	var b bytes.Buffer
+
+	writer, err := ical.NewWriter(&b, prodID, task.Category)
+	if err != nil {
+		return "", err
+	}
+	if err := writer.WriteComponent(feed.Todo(task, time.Now())); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// etag - returns the entity tag of a task, it changes whenever the task is updated.
+//
+//	@param task - ts.Task
+//	@return string
+func etag(task ts.Task) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v/%v", task.ID, task.UpdatedAt.UnixMicro())))
+	return fmt.Sprintf("%q", hex.EncodeToString(sum[:16]))
+}
+
+// response - returns the multistatus response of the resource.
+// Found properties are returned with a 200 status, the others with a 404 status.
+//
+//	@param names - the requested properties
+//	@param all - return every property
+//	@param namesOnly - return the property names without values
+//	@return response
+func (r *resource) response(names []xml.Name, all, namesOnly bool) response {
+	var found, missing []property
+
+	if all || namesOnly {
+		for name, value := range r.Props {
+			if namesOnly {
+				value = ""
+			}
+			found = append(found, property{XMLName: name, InnerXML: value})
+		}
+	} else {
+		for _, name := range names {
+			if value, ok := r.Props[name]; ok {
+				found = append(found, property{XMLName: name, InnerXML: value})
+			} else {
+				missing = append(missing, property{XMLName: name})
+			}
+		}
+	}
+
+	res := response{Href: r.Href}
+	if len(found) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Prop: prop{Values: found}, Status: status(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		res.Propstats = append(res.Propstats, propstat{Prop: prop{Values: missing}, Status: status(http.StatusNotFound)})
+	}
+
+	return res
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"encore.app/pkg/color"
 	"encore.app/pkg/database"
 	"encore.app/pkg/pagination"
 )
@@ -18,6 +19,34 @@ import (
 // get the service name
 var categoriesDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
 
+// nameConstraint - the unique index on the lower case names of a user's categories
+const nameConstraint = "categories_uid_name_key"
+
+// saveError - maps the violation of the unique names to ErrAlreadyExists.
+//
+// @param err - error
+// @param action - what was done, for the error message
+// @return error
+func saveError(err error, action string) error {
+	if database.IsUniqueViolation(err, nameConstraint) {
+		return ErrAlreadyExists
+	}
+	return fmt.Errorf("%v: %w", action, err)
+}
+
+// withTaskCounts - the categories with the number of open and completed tasks in each, counted in the same query
+const withTaskCounts = `(
+    SELECT categories.*, counts.open_tasks, counts.completed_tasks
+    FROM categories
+    CROSS JOIN LATERAL (
+      SELECT
+        COUNT(*) FILTER (WHERE NOT completed AND NOT archived) AS open_tasks,
+        COUNT(*) FILTER (WHERE completed) AS completed_tasks
+      FROM tasks
+      WHERE tasks.category_id = categories.id
+    ) counts
+  ) categories`
+
 // FindByOneField - get user by field
 //
 //	@param ctx - context.Context
@@ -72,12 +101,12 @@ func FindManyByField(ctx context.Context, field, ops string, value any) ([]Categ
 	// declare categorys
 	var categories []Category
 	// execute query
-	if err := database.NamedStructQuery(ctx, categoriesDatabase, q, data, &categories); err != nil {
-		if err == database.ErrNotFound {
-			return []Category{}, ErrNotFound
-		}
+	if err := database.NamedSliceQuery(ctx, categoriesDatabase, q, data, &categories); err != nil {
 		return []Category{}, fmt.Errorf("selecting categorys by ID[%v]: %w", value, err)
 	}
+	if len(categories) < 1 {
+		return []Category{}, ErrNotFound
+	}
 
 	return categories, nil
 }
@@ -86,37 +115,174 @@ func FindManyByField(ctx context.Context, field, ops string, value any) ([]Categ
 //
 //	@param ctx - context.Context
 //	@param payload - *CreateCategoryPayload
+//	@return *Category
 //	@return error
-func Create(ctx context.Context, uid string, payload *CreateCategoryPayload) error {
-	// check if category already exists
-	cat, err := FindOneByField(ctx, "name", "=", strings.ToLower(payload.Name))
-	if err == nil {
-		return err
-	}
-	// check if category ID is empty (if not, category already exists)
-	if len(cat.ID) > 0 {
-		return ErrAlreadyExists
-	}
+func Create(ctx context.Context, uid string, payload *CreateCategoryPayload) (*Category, error) {
+	var err error
 
-	// create category
+	// create category, a category with the name of another category of the user is rejected by the database
 	category := Category{
 		ID:          uuid.New().String(),
 		UID:         uid,
-		Name:        strings.ToLower(payload.Name),
+		Name:        strings.ToLower(strings.TrimSpace(payload.Name)),
 		Description: payload.Description,
+		Color:       color.DefaultCategory,
+		Icon:        strings.TrimSpace(payload.Icon),
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	// query statement to be executed
+	// set the color if given
+	if len(strings.TrimSpace(payload.Color)) > 0 {
+		if category.Color, err = color.Normalize(payload.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	// nest the category in its parent if given
+	if len(payload.ParentID) > 0 {
+		category.ParentID = &payload.ParentID
+		if err := checkParent(ctx, categoriesDatabase, &category); err != nil {
+			return nil, err
+		}
+	}
+
+	// query statement to be executed, new categories come last
 	query := `
-    INSERT INTO categories (id, uid, name, description, created_at, updated_at)
-    VALUES (:id, :uid, :name, :description, :created_at, :updated_at)
+    INSERT INTO categories (id, uid, name, description, color, icon, position, parent_id, created_at, updated_at)
+    VALUES (
+      :id, :uid, :name, :description, :color, :icon,
+      COALESCE((SELECT MAX(position) + 1 FROM categories WHERE uid = :uid), 0), :parent_id, :created_at, :updated_at
+    )
+    RETURNING position
   `
 
 	// create category
-	if err := database.NamedExecQuery(ctx, categoriesDatabase, query, category); err != nil {
-		return fmt.Errorf("creating category: %w", err)
+	var created struct {
+		Position int `db:"position"`
+	}
+	if err := database.NamedStructQuery(ctx, categoriesDatabase, query, category, &created); err != nil {
+		return nil, saveError(err, "creating category")
+	}
+	category.Position = created.Position
+
+	return &category, nil
+}
+
+// checkParent - checks that a category can be nested in its parent: the parent must be another category of the user
+// that is not one of its descendants, and the category and its descendants must stay within MaxDepth levels.
+//
+// @param ctx - context.Context
+// @param db - database connection or transaction
+// @param category - *Category
+// @return error
+func checkParent(ctx context.Context, db sqlx.ExtContext, category *Category) error {
+	// top level categories need no checks
+	if category.ParentID == nil {
+		return nil
+	}
+
+	// query statement to be executed, the recursion is bounded in case the rows already form a cycle
+	query := `
+    WITH RECURSIVE ancestors AS (
+      SELECT id, parent_id, 1 AS level FROM categories WHERE id = CAST(:parent_id AS UUID) AND uid = :uid
+      UNION ALL
+      SELECT categories.id, categories.parent_id, ancestors.level + 1
+      FROM categories JOIN ancestors ON categories.id = ancestors.parent_id
+      WHERE ancestors.level <= :max_depth
+    ), descendants AS (
+      SELECT id, 0 AS level FROM categories WHERE id = CAST(:id AS UUID)
+      UNION ALL
+      SELECT categories.id, descendants.level + 1
+      FROM categories JOIN descendants ON categories.parent_id = descendants.id
+      WHERE descendants.level <= :max_depth
+    )
+    SELECT
+      (SELECT COUNT(*) FROM ancestors) AS depth,
+      (SELECT COUNT(*) FROM ancestors WHERE id = CAST(:id AS UUID)) AS cycles,
+      (SELECT COALESCE(MAX(level), 0) FROM descendants) AS height
+  `
+
+	var nesting struct {
+		Depth  int `db:"depth"`  // the level of the parent, from 1
+		Cycles int `db:"cycles"` // the category is an ancestor of its parent
+		Height int `db:"height"` // the levels of descendants below the category
+	}
+	// execute query
+	if err := database.NamedStructQuery(ctx, db, query, map[string]any{
+		"id":        category.ID,
+		"uid":       category.UID,
+		"parent_id": *category.ParentID,
+		"max_depth": MaxDepth,
+	}, &nesting); err != nil {
+		return fmt.Errorf("selecting parent category: %w", err)
+	}
+
+	switch {
+	case nesting.Depth < 1:
+		return ErrParentNotFound
+	case nesting.Cycles > 0:
+		return ErrCycle
+	case nesting.Depth+1+nesting.Height > MaxDepth:
+		return ErrTooDeep
+	}
+
+	return nil
+}
+
+// Insert - Insert is a function that inserts a complete category row.
+// It accepts a transaction so categories can be created together with other rows.
+//
+// @param ctx - context.Context
+// @param db - database connection or transaction
+// @param category - *Category
+// @return error
+func Insert(ctx context.Context, db sqlx.ExtContext, category *Category) error {
+	// categories without a color have the default one
+	if len(category.Color) < 1 {
+		category.Color = color.DefaultCategory
+	}
+	if err := checkParent(ctx, db, category); err != nil {
+		return err
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO categories (id, uid, name, description, color, icon, position, parent_id, created_at, updated_at)
+    VALUES (:id, :uid, :name, :description, :color, :icon, :position, :parent_id, :created_at, :updated_at)
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, db, query, category); err != nil {
+		return saveError(err, "inserting category")
+	}
+
+	return nil
+}
+
+// Replace - Replace is a function that overwrites all editable columns of a category.
+// It accepts a transaction so categories can be replaced together with other rows.
+//
+// @param ctx - context.Context
+// @param db - database connection or transaction
+// @param category - *Category
+// @return error
+func Replace(ctx context.Context, db sqlx.ExtContext, category *Category) error {
+	if err := checkParent(ctx, db, category); err != nil {
+		return err
+	}
+
+	// query statement to be executed
+	query := `
+    UPDATE categories SET
+      name = :name, description = :description, color = :color, icon = :icon, position = :position,
+      parent_id = :parent_id, updated_at = :updated_at
+    WHERE id = :id AND uid = :uid
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, db, query, category); err != nil {
+		return saveError(err, "replacing category")
 	}
 
 	return nil
@@ -126,15 +292,32 @@ func Create(ctx context.Context, uid string, payload *CreateCategoryPayload) err
 //
 // @param ctx - context.Context
 // @param id - string
+// @param fields - comma separated fields to return, all when empty
 // @return category
 // @return error
-func Get(ctx context.Context, id string) (*Category, error) {
-	// check if category exists
-	category, err := FindOneByField(ctx, "id", "=", id)
+func Get(ctx context.Context, id, fields string) (*Category, error) {
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(fields, selectableColumns)
 	if err != nil {
+		return nil, err
+	}
+
+	// query statement to be executed
+	q := fmt.Sprintf("SELECT %v FROM %v WHERE id = :id LIMIT 1", fieldset.Select("id"), withTaskCounts)
+
+	// declare category
+	var category Category
+	// execute query
+	if err := database.NamedStructQuery(ctx, categoriesDatabase, q, map[string]any{"id": id}, &category); err != nil {
+		if err == database.ErrNotFound {
+			return nil, ErrNotFound
+		}
 		return nil, fmt.Errorf("selecting category: %w", err)
 	}
 
+	// only marshal the requested fields
+	category.fieldset = fieldset
+
 	// return category
 	return &category, nil
 }
@@ -146,10 +329,13 @@ func Get(ctx context.Context, id string) (*Category, error) {
 // @return categories
 // @return error
 func GetMany(ctx context.Context, ids []string) ([]Category, error) {
-	// check if category exists
-	categories, err := FindManyByField(ctx, "id", "=", ids)
-	if err != nil {
-		return nil, fmt.Errorf("selecting category: %w", err)
+	// query statement to be executed
+	q := "SELECT * FROM categories WHERE id = ANY(:ids)"
+
+	// execute query
+	categories := []Category{}
+	if err := database.NamedSliceQuery(ctx, categoriesDatabase, q, map[string]any{"ids": ids}, &categories); err != nil {
+		return nil, fmt.Errorf("selecting categories: %w", err)
 	}
 
 	// return category
@@ -157,23 +343,65 @@ func GetMany(ctx context.Context, ids []string) ([]Category, error) {
 }
 
 // Delete - Delete is a function that deletes a category.
+// Its tasks are moved to another category of the user, deleted or left without a category, depending on options.
 //
 // @param ctx - context.Context
 // @param id - string
+// @param options - *DeleteCategoryOptions
 // @return error
-func Delete(ctx context.Context, id string) error {
-	// query statement to be executed
-	q := "DELETE FROM categories WHERE id = :id"
-
-	// execute query
-	if err := database.NamedExecQuery(ctx, categoriesDatabase, q, map[string]any{
-		"id": id,
-	}); err != nil {
-		return fmt.Errorf("deleting task: %w", err)
+func Delete(ctx context.Context, id string, options *DeleteCategoryOptions) error {
+	if options == nil {
+		options = &DeleteCategoryOptions{}
 	}
 
-	// Delete was successful
-	return nil
+	return database.Transaction(ctx, categoriesDatabase, func(tx *sqlx.Tx) error {
+		data := map[string]any{
+			"id":          id,
+			"reassign_to": nil,
+			"now":         time.Now().UTC(),
+		}
+
+		// the tasks can only move to another category of the same user
+		if len(options.ReassignTo) > 0 {
+			count, err := database.NamedCountQuery(ctx, tx, `
+        SELECT COUNT(*) FROM categories target
+        JOIN categories deleted ON deleted.uid = target.uid
+        WHERE target.id = CAST(:reassign_to AS UUID) AND deleted.id = :id AND target.id <> deleted.id
+      `, map[string]any{"id": id, "reassign_to": options.ReassignTo})
+			if err != nil {
+				return fmt.Errorf("selecting category: %w", err)
+			}
+			if count < 1 {
+				return ErrInvalidReassign
+			}
+			data["reassign_to"] = options.ReassignTo
+		}
+
+		// the nested categories move up to the parent of the category
+		if err := database.NamedExecQuery(ctx, tx, `
+      UPDATE categories SET parent_id = (SELECT parent_id FROM categories WHERE id = :id), updated_at = :now
+      WHERE parent_id = :id
+    `, data); err != nil {
+			return fmt.Errorf("updating nested categories: %w", err)
+		}
+
+		// query statement to be executed
+		q := "UPDATE tasks SET category_id = CAST(:reassign_to AS UUID), updated_at = :now WHERE category_id = :id"
+		if options.DeleteTasks {
+			q = "DELETE FROM tasks WHERE category_id = :id"
+		}
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, q, data); err != nil {
+			return fmt.Errorf("updating tasks of category: %w", err)
+		}
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM categories WHERE id = :id", data); err != nil {
+			return fmt.Errorf("deleting category: %w", err)
+		}
+
+		// Delete was successful
+		return nil
+	})
 }
 
 // DeleteMany - DeleteMany is a function that deletes many categories.
@@ -236,16 +464,27 @@ func Update(ctx context.Context, id string, payload *UpdateCategoryPayload) erro
 		return fmt.Errorf("selecting category: %w", err)
 	}
 
+	// validate and normalize the color, names are stored in lower case
+	if len(strings.TrimSpace(payload.Color)) > 0 {
+		if payload.Color, err = color.Normalize(payload.Color); err != nil {
+			return err
+		}
+	}
+	payload.Name = strings.ToLower(strings.TrimSpace(payload.Name))
+
 	// map for query fields
 	fields := map[string]any{}
 
 	// if not empty, update category field
-	vp := reflect.ValueOf(payload)
+	vp := reflect.ValueOf(payload).Elem()
 
 	// loop through payload fields and check for empty values
 	for i := 0; i < vp.NumField(); i++ {
-		// get the db tag name of the field
+		// get the db tag name of the field, the parent is set below
 		field := vp.Type().Field(i).Tag.Get("db")
+		if field == "-" {
+			continue
+		}
 		// get the value of the field
 		value := vp.Field(i).Interface()
 
@@ -261,30 +500,68 @@ func Update(ctx context.Context, id string, payload *UpdateCategoryPayload) erro
 	fields["updated_at"] = time.Now().UTC()
 	fields["id"] = category.ID
 
-	// loop through fields and create query fields
-	for k := range fields {
-		ks = append(ks, fmt.Sprintf("%v = :%v", k, k))
-	}
+	return database.Transaction(ctx, categoriesDatabase, func(tx *sqlx.Tx) error {
+		// move the category if a parent is given, the user's categories are locked so moves cannot form a cycle
+		if payload.ParentID != nil {
+			category.ParentID = nil
+			if len(*payload.ParentID) > 0 {
+				category.ParentID = payload.ParentID
+			}
+			if err := database.NamedExecQuery(ctx, tx, "SELECT id FROM categories WHERE uid = :uid FOR UPDATE", map[string]any{
+				"uid": category.UID,
+			}); err != nil {
+				return fmt.Errorf("locking categories: %w", err)
+			}
+			if err := checkParent(ctx, tx, &category); err != nil {
+				return err
+			}
+			fields["parent_id"] = category.ParentID
+		}
 
-	// query statement to be executed
-	q := fmt.Sprintf("UPDATE categories SET %v WHERE id = :id RETURNING *", strings.Join(ks, ", "))
+		// loop through fields and create query fields
+		for k := range fields {
+			ks = append(ks, fmt.Sprintf("%v = :%v", k, k))
+		}
 
-	// execute query
-	if err := database.NamedExecQuery(ctx, categoriesDatabase, q, fields); err != nil {
-		return fmt.Errorf("updating category: %w", err)
-	}
+		// query statement to be executed
+		q := fmt.Sprintf("UPDATE categories SET %v WHERE id = :id RETURNING *", strings.Join(ks, ", "))
 
-	// return category
-	return nil
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, q, fields); err != nil {
+			return saveError(err, "updating category")
+		}
+
+		// return category
+		return nil
+	})
 }
 
 // GetUserTasks - GetUserTasks is a function that gets a user's categories.
 //
+// When options carry a cursor, keyset pagination is used instead of page numbers.
+//
 // @param ctx - context.Context
 // @param uid - string
 // @return categories
 // @return error
 func GetUserCategories(ctx context.Context, uid string, options *pagination.Options) (*PaginatedCategoriesResponse, error) {
+	// validate the sort order
+	sorting, err := options.Sorting(sortableColumns, "ASC", "position")
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(options.Fields, selectableColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	// use keyset pagination when a cursor is provided
+	if options.UsesCursor() {
+		return getUserCategoriesAfterCursor(ctx, uid, options, sorting, fieldset)
+	}
+
 	// declare categories
 	var categories []Category = []Category{}
 
@@ -299,9 +576,9 @@ func GetUserCategories(ctx context.Context, uid string, options *pagination.Opti
 		return nil, fmt.Errorf("counting categories: %w", err)
 	}
 
-	// set limit to 20 if it is less than 0 or greater than count
-	if options.Limit < 1 || options.Limit > count {
-		options.Limit = 20
+	// set limit to 20 if it is less than 0, greater than count or greater than the maximum
+	if options.Limit < 1 || options.Limit > count || options.Limit > pagination.MaxLimit {
+		options.Limit = pagination.DefaultLimit
 	}
 
 	// calculate for pagination
@@ -315,12 +592,12 @@ func GetUserCategories(ctx context.Context, uid string, options *pagination.Opti
 	}
 
 	// query statement to be executed
-	query := `
-    SELECT * FROM categories
+	query := fmt.Sprintf(`
+    SELECT %v FROM %v
     WHERE uid = :uid
-    ORDER BY created_at
-    DESC LIMIT :limit OFFSET :offset
-  `
+    ORDER BY %v
+    LIMIT :limit OFFSET :offset
+  `, fieldset.Select(sorting.Names()...), withTaskCounts, sorting.OrderBy())
 
 	p := struct {
 		UID    string `db:"uid" json:"uid" validate:"required" url:"uid"`
@@ -337,33 +614,325 @@ func GetUserCategories(ctx context.Context, uid string, options *pagination.Opti
 		return nil, fmt.Errorf("selecting categories: %w", err)
 	}
 
+	// create a cursor from the last category so clients can switch to keyset pagination
+	nextCursor, err := pagination.NextCursor(sorting, categories, paging.HasNext())
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	// only marshal the requested fields
+	for i := range categories {
+		categories[i].fieldset = fieldset
+	}
+
 	return &PaginatedCategoriesResponse{
 		TotalPages:  paging.Pages(),
 		Total:       paging.Total(),
 		CurrentPage: paging.Page(),
+		NextCursor:  nextCursor,
 		Categories:  categories,
 	}, nil
 }
 
-// DeleteAllUserCategories - DeleteAllUserCategories is a function that deletes all categories with a user ID.
+// getUserCategoriesAfterCursor - gets the page of a user's categories that follows the cursor.
 //
 // @param ctx - context.Context
 // @param uid - string
+// @param options - *pagination.Options
+// @param sorting - *pagination.Sorting
+// @param fieldset - *pagination.Fieldset
+// @return categories
 // @return error
-func DeleteAllUserCategories(ctx context.Context, uid string) error {
+func getUserCategoriesAfterCursor(ctx context.Context, uid string, options *pagination.Options, sorting *pagination.Sorting, fieldset *pagination.Fieldset) (*PaginatedCategoriesResponse, error) {
+	// declare categories
+	var categories []Category = []Category{}
+
+	// decode and verify the cursor
+	cursor, err := pagination.DecodeCursor(options.Cursor, sorting.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	// condition selecting the categories after the cursor
+	after, args, err := sorting.After(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// set limit to the default if it is out of range
+	if options.Limit < 1 || options.Limit > pagination.MaxLimit {
+		options.Limit = pagination.DefaultLimit
+	}
+
+	// query statement to be executed (one extra row tells if there is a next page)
+	query := fmt.Sprintf(`
+    SELECT %v FROM %v
+    WHERE uid = :uid AND %v
+    ORDER BY %v
+    LIMIT :limit
+  `, fieldset.Select(sorting.Names()...), withTaskCounts, after, sorting.OrderBy())
+
+	args["uid"] = uid
+	args["limit"] = options.Limit + 1
+
+	// execute query
+	if err := database.NamedSliceQuery(ctx, categoriesDatabase, query, args, &categories); err != nil {
+		return nil, fmt.Errorf("selecting categories: %w", err)
+	}
+
+	// drop the extra row
+	hasNext := len(categories) > options.Limit
+	if hasNext {
+		categories = categories[:options.Limit]
+	}
+
+	// create the cursor for the next page
+	nextCursor, err := pagination.NextCursor(sorting, categories, hasNext)
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	// only marshal the requested fields
+	for i := range categories {
+		categories[i].fieldset = fieldset
+	}
+
+	return &PaginatedCategoriesResponse{
+		NextCursor: nextCursor,
+		Categories: categories,
+	}, nil
+}
+
+// StreamUserCategories - StreamUserCategories is a function that passes all of a user's categories to fn one at a time.
+// It honours the sort order and fields of the options, pagination is ignored.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param options - *pagination.Options
+// @param fn - func(Category) error
+// @return error
+func StreamUserCategories(ctx context.Context, uid string, options *pagination.Options, fn func(Category) error) error {
+	// validate the sort order
+	sorting, err := options.Sorting(sortableColumns, "ASC", "position")
+	if err != nil {
+		return err
+	}
+
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(options.Fields, selectableColumns)
+	if err != nil {
+		return err
+	}
+
 	// query statement to be executed
-	q := `
-    DELETE FROM categories
+	query := fmt.Sprintf(`
+    SELECT %v FROM %v
     WHERE uid = :uid
-  `
+    ORDER BY %v
+  `, fieldset.Select(sorting.Names()...), withTaskCounts, sorting.OrderBy())
 
 	// execute query
-	if err := database.NamedExecQuery(ctx, categoriesDatabase, q, map[string]any{
-		"uid": uid,
+	return database.NamedStreamQuery(ctx, categoriesDatabase, query, map[string]any{"uid": uid}, func(category Category) error {
+		// only marshal the requested fields
+		category.fieldset = fieldset
+		return fn(category)
+	})
+}
+
+// GetUserCategoryTree - GetUserCategoryTree is a function that gets all of a user's categories nested in their parents.
+// Siblings follow the sort order of the options, pagination and fields are ignored.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param options - *pagination.Options
+// @return categories - the top level categories
+// @return count - the number of categories
+// @return error
+func GetUserCategoryTree(ctx context.Context, uid string, options *pagination.Options) ([]Category, int, error) {
+	categories := []Category{}
+	if err := StreamUserCategories(ctx, uid, &pagination.Options{Sort: options.Sort, Order: options.Order}, func(category Category) error {
+		categories = append(categories, category)
+		return nil
 	}); err != nil {
-		return fmt.Errorf("deleting categories: %w", err)
+		return nil, 0, err
 	}
 
-	// Delete was successful
-	return nil
+	return tree(categories), len(categories), nil
+}
+
+// Merge - Merge is a function that moves the tasks of categories to a target category and deletes them.
+// The categories nested in them move to the target, which moves up when it was nested in one of them.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param targetID - the category the tasks move to
+// @param sourceIDs - the categories to delete
+// @return error
+func Merge(ctx context.Context, uid, targetID string, sourceIDs []string) error {
+	data := map[string]any{
+		"uid":        uid,
+		"target_id":  targetID,
+		"source_ids": sourceIDs,
+		"max_depth":  MaxDepth,
+		"now":        time.Now().UTC(),
+	}
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return ErrInvalidMerge
+		}
+	}
+
+	return database.Transaction(ctx, categoriesDatabase, func(tx *sqlx.Tx) error {
+		// lock the user's categories so the tree does not change while it is merged
+		if err := database.NamedExecQuery(ctx, tx, "SELECT id FROM categories WHERE uid = :uid FOR UPDATE", data); err != nil {
+			return fmt.Errorf("locking categories: %w", err)
+		}
+
+		// every id must be a category of the user
+		count, err := database.NamedCountQuery(ctx, tx, `
+      SELECT COUNT(*) FROM categories
+      WHERE uid = :uid AND (id = ANY(CAST(:source_ids AS UUID[])) OR id = CAST(:target_id AS UUID))
+    `, data)
+		if err != nil {
+			return fmt.Errorf("counting categories: %w", err)
+		}
+		if count != len(sourceIDs)+1 {
+			return ErrNotFound
+		}
+
+		// the target takes the place of its nearest ancestor that is not merged
+		if err := database.NamedExecQuery(ctx, tx, `
+      WITH RECURSIVE ancestors AS (
+        SELECT parent_id AS id, 1 AS level FROM categories WHERE id = CAST(:target_id AS UUID)
+        UNION ALL
+        SELECT categories.parent_id, ancestors.level + 1
+        FROM categories JOIN ancestors ON categories.id = ancestors.id
+        WHERE ancestors.id = ANY(CAST(:source_ids AS UUID[])) AND ancestors.level <= :max_depth
+      )
+      UPDATE categories SET parent_id = (
+        SELECT id FROM ancestors WHERE id IS NULL OR NOT id = ANY(CAST(:source_ids AS UUID[])) ORDER BY level LIMIT 1
+      ), updated_at = :now
+      WHERE id = CAST(:target_id AS UUID) AND parent_id = ANY(CAST(:source_ids AS UUID[]))
+    `, data); err != nil {
+			return fmt.Errorf("moving target category: %w", err)
+		}
+
+		// the nested categories and the tasks move to the target
+		if err := database.NamedExecQuery(ctx, tx, `
+      UPDATE categories SET parent_id = CAST(:target_id AS UUID), updated_at = :now
+      WHERE parent_id = ANY(CAST(:source_ids AS UUID[])) AND id <> CAST(:target_id AS UUID)
+    `, data); err != nil {
+			return fmt.Errorf("moving nested categories: %w", err)
+		}
+		if err := database.NamedExecQuery(ctx, tx, `
+      UPDATE tasks SET category_id = CAST(:target_id AS UUID), updated_at = :now
+      WHERE category_id = ANY(CAST(:source_ids AS UUID[]))
+    `, data); err != nil {
+			return fmt.Errorf("moving tasks: %w", err)
+		}
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM categories WHERE id = ANY(CAST(:source_ids AS UUID[]))", data); err != nil {
+			return fmt.Errorf("deleting categories: %w", err)
+		}
+
+		// the categories moved to the target must stay within the depth
+		depth, err := database.NamedCountQuery(ctx, tx, `
+      WITH RECURSIVE levels AS (
+        SELECT id, 1 AS level FROM categories WHERE uid = :uid AND parent_id IS NULL
+        UNION ALL
+        SELECT categories.id, levels.level + 1 FROM categories JOIN levels ON categories.parent_id = levels.id
+        WHERE levels.level <= :max_depth
+      )
+      SELECT COALESCE(MAX(level), 0) FROM levels
+    `, data)
+		if err != nil {
+			return fmt.Errorf("selecting category depth: %w", err)
+		}
+		if depth > MaxDepth {
+			return ErrTooDeep
+		}
+
+		return nil
+	})
+}
+
+// Reorder - Reorder is a function that numbers a user's categories in the given order.
+// Categories left out follow the given ones in their current order.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param ids - the ids of the categories in their new order
+// @return categories - the categories whose position changed
+// @return error
+func Reorder(ctx context.Context, uid string, ids []string) ([]Category, error) {
+	data := map[string]any{"uid": uid, "ids": ids, "updated_at": time.Now().UTC()}
+	categories := []Category{}
+
+	err := database.Transaction(ctx, categoriesDatabase, func(tx *sqlx.Tx) error {
+		// every id must be a category of the user
+		count, err := database.NamedCountQuery(ctx, tx, "SELECT COUNT(*) FROM categories WHERE uid = :uid AND id = ANY(CAST(:ids AS UUID[]))", data)
+		if err != nil {
+			return fmt.Errorf("counting categories: %w", err)
+		}
+		if count != len(ids) {
+			return ErrNotFound
+		}
+
+		// query statement to be executed
+		query := `
+      UPDATE categories SET position = ordered.position, updated_at = :updated_at
+      FROM (
+        SELECT id, ROW_NUMBER() OVER (
+          ORDER BY COALESCE(ARRAY_POSITION(CAST(:ids AS UUID[]), id), 2147483647), position, created_at, id
+        ) - 1 AS position
+        FROM categories
+        WHERE uid = :uid
+      ) ordered
+      WHERE categories.id = ordered.id AND categories.position <> ordered.position
+      RETURNING categories.*
+    `
+
+		// execute query
+		if err := database.NamedSliceQuery(ctx, tx, query, data, &categories); err != nil {
+			return fmt.Errorf("reordering categories: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// DeleteAllUserCategories - DeleteAllUserCategories is a function that deletes all categories with a user ID.
+// The tasks of the user are left without a category.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @return error
+func DeleteAllUserCategories(ctx context.Context, uid string) error {
+	return database.Transaction(ctx, categoriesDatabase, func(tx *sqlx.Tx) error {
+		data := map[string]any{
+			"uid": uid,
+			"now": time.Now().UTC(),
+		}
+
+		// query statement to be executed
+		q := `
+      UPDATE tasks SET category_id = NULL, updated_at = :now
+      WHERE uid = :uid AND category_id IS NOT NULL
+    `
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, q, data); err != nil {
+			return fmt.Errorf("updating tasks of categories: %w", err)
+		}
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM categories WHERE uid = :uid", data); err != nil {
+			return fmt.Errorf("deleting categories: %w", err)
+		}
+
+		// Delete was successful
+		return nil
+	})
 }
@@ -0,0 +1,83 @@
+//go:build integration
+
+package cs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"encore.app/pkg/pagination"
+)
+
+// run with a database: encore test -tags integration ./tasks/cs/...
+func TestReorderThenList(t *testing.T) {
+	ctx := context.Background()
+	uid := uuid.New().String()
+	t.Cleanup(func() { _ = DeleteAllUserCategories(ctx, uid) })
+
+	ids := map[string]string{}
+	for _, name := range []string{"work", "personal", "shopping"} {
+		category, err := Create(ctx, uid, &CreateCategoryPayload{Name: name})
+		if err != nil {
+			t.Fatalf("Create(%q) failed: %v", name, err)
+		}
+		ids[name] = category.ID
+	}
+
+	// shopping first, the categories left out keep their order after it
+	if _, err := Reorder(ctx, uid, []string{ids["shopping"]}); err != nil {
+		t.Fatalf("Reorder() failed: %v", err)
+	}
+	want := []string{"shopping", "work", "personal"}
+
+	names := func(categories []Category) []string {
+		list := []string{}
+		for _, category := range categories {
+			list = append(list, category.Name)
+		}
+		return list
+	}
+	check := func(listing string, got []string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%v = %q, want %q", listing, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%v = %q, want %q", listing, got, want)
+				return
+			}
+		}
+	}
+
+	page, err := GetUserCategories(ctx, uid, &pagination.Options{})
+	if err != nil {
+		t.Fatalf("GetUserCategories() failed: %v", err)
+	}
+	check("GetUserCategories()", names(page.Categories))
+
+	var streamed []Category
+	if err := StreamUserCategories(ctx, uid, &pagination.Options{}, func(category Category) error {
+		streamed = append(streamed, category)
+		return nil
+	}); err != nil {
+		t.Fatalf("StreamUserCategories() failed: %v", err)
+	}
+	check("StreamUserCategories()", names(streamed))
+
+	tree, _, err := GetUserCategoryTree(ctx, uid, &pagination.Options{})
+	if err != nil {
+		t.Fatalf("GetUserCategoryTree() failed: %v", err)
+	}
+	check("GetUserCategoryTree()", names(tree))
+
+	// the requested order still wins
+	page, err = GetUserCategories(ctx, uid, &pagination.Options{Order: "desc"})
+	if err != nil {
+		t.Fatalf("GetUserCategories() failed: %v", err)
+	}
+	want = []string{"personal", "work", "shopping"}
+	check("GetUserCategories(desc)", names(page.Categories))
+}
@@ -1,8 +1,23 @@
 package cs
 
-import "errors"
+import (
+	"errors"
+
+	"encore.dev/beta/errs"
+)
 
 var (
-	ErrNotFound      = errors.New("category not found")
-	ErrAlreadyExists = errors.New("category already exists")
+	ErrNotFound = errors.New("category not found")
+	// ErrAlreadyExists - the user has a category with the name, names are compared case-insensitively
+	ErrAlreadyExists = &errs.Error{Code: errs.AlreadyExists, Message: "category already exists"}
+	// ErrInvalidReassign - tasks can only be moved to another category of the same user
+	ErrInvalidReassign = errors.New("tasks can only be reassigned to another category of the user")
+	// ErrParentNotFound - the parent is not a category of the user
+	ErrParentNotFound = errors.New("parent category not found")
+	// ErrCycle - a category cannot be nested in itself or one of its descendants
+	ErrCycle = errors.New("a category cannot be nested in itself or its descendants")
+	// ErrTooDeep - categories can be nested MaxDepth levels deep
+	ErrTooDeep = errors.New("categories cannot be nested this deep")
+	// ErrInvalidMerge - a category cannot be merged into itself
+	ErrInvalidMerge = errors.New("a category cannot be merged into itself")
 )
@@ -2,34 +2,204 @@ package cs
 
 import (
 	"time"
+
+	"encore.app/pkg/events"
+	"encore.app/pkg/pagination"
 )
 
+// sortableColumns - the fields categories can be sorted by
+var sortableColumns = map[string]pagination.Column{
+	"createdAt": {Name: "created_at", Type: "TIMESTAMP"},
+	"updatedAt": {Name: "updated_at", Type: "TIMESTAMP"},
+	"name":      {Name: "name", Type: "TEXT"},
+	"position":  {Name: "position", Type: "INTEGER"},
+}
+
+// selectableColumns - the fields clients can select
+var selectableColumns = pagination.Selectable(Category{})
+
+// MaxDepth - the most levels categories can be nested in, top level categories included
+const MaxDepth = 5
+
 type Category struct {
 	ID          string    `json:"id" db:"id"`
 	UID         string    `json:"uid" db:"uid"`
 	Name        string    `json:"name" db:"name"`
 	Description string    `json:"description" db:"description"`
+	Color       string    `json:"color" db:"color"`        // a palette color name or "#rrggbb", default: "#00b3ff"
+	Icon        string    `json:"icon" db:"icon"`          // an emoji or icon name, empty -> no icon
+	Position    int       `json:"position" db:"position"`  // the user-defined order, from 0
+	ParentID    *string   `json:"parentId" db:"parent_id"` // nil -> top level
 	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+
+	OpenTasks      int `json:"openTasks" db:"open_tasks"`           // tasks neither completed nor archived
+	CompletedTasks int `json:"completedTasks" db:"completed_tasks"` // completed tasks, archived ones included
+
+	Children []Category `json:"children,omitempty" db:"-"` // the nested categories, only set in trees
+
+	fieldset *pagination.Fieldset // the fields to marshal, all when nil
+}
+
+// MarshalJSON - marshals the category keeping only the requested fields
+func (c Category) MarshalJSON() ([]byte, error) {
+	type category Category
+	return c.fieldset.Marshal(category(c))
 }
 
 type CreateCategoryPayload struct {
 	Name        string `json:"name" validate:"required"`
 	Description string `json:"description" validate:"omitempty"`
+	Color       string `json:"color" validate:"omitempty"`         // a palette color, hex or rgb(r, g, b), default: "#00b3ff"
+	Icon        string `json:"icon" validate:"omitempty,max=32"`   // an emoji or icon name
+	ParentID    string `json:"parentId" validate:"omitempty,uuid"` // optional, nest the category in another category of the user
 }
 
 type UpdateCategoryPayload struct {
-	Name        string `json:"name" db:"name" validate:"omitempty"`
-	Description string `json:"description" db:"description" validate:"omitempty"`
+	Name        string  `json:"name" db:"name" validate:"omitempty"`
+	Description string  `json:"description" db:"description" validate:"omitempty"`
+	Color       string  `json:"color" db:"color" validate:"omitempty"`         // a palette color, hex or rgb(r, g, b)
+	Icon        string  `json:"icon" db:"icon" validate:"omitempty,max=32"`    // an emoji or icon name
+	ParentID    *string `json:"parentId" db:"-" validate:"omitempty,uuid|eq="` // optional, empty to move the category to the top level
+}
+
+// ReorderCategoriesPayload - the new order of a user's categories
+type ReorderCategoriesPayload struct {
+	// the categories in their new order, categories left out follow them in their current order
+	IDs []string `json:"ids" validate:"required,min=1,max=500,unique,dive,uuid"`
+}
+
+// MergeCategoriesPayload - the categories to merge into a target
+type MergeCategoriesPayload struct {
+	TargetID  string   `json:"targetId" validate:"required,uuid"`                            // the category the tasks move to
+	SourceIDs []string `json:"sourceIds" validate:"required,min=1,max=100,unique,dive,uuid"` // the categories to delete
+}
+
+// DeleteCategoryOptions - what happens to the tasks of a deleted category, they are left without a category by default
+type DeleteCategoryOptions struct {
+	ReassignTo  string `json:"reassignTo" url:"reassignTo" validate:"omitempty,uuid,excluded_with=DeleteTasks"` // move the tasks to this category of the user
+	DeleteTasks bool   `json:"deleteTasks" url:"deleteTasks"`                                                   // delete the tasks with the category
+}
+
+// ListOptions - the pagination options of a category listing and whether it is a tree
+type ListOptions struct {
+	Limit  int    `json:"limit" url:"limit"`   // the number of items
+	Page   int    `json:"page" url:"page"`     // the page
+	Cursor string `json:"cursor" url:"cursor"` // the cursor to continue from (keyset pagination)
+	Sort   string `json:"sort" url:"sort"`     // comma separated fields to sort by
+	Order  string `json:"order" url:"order"`   // the sort direction: asc, desc, default: "asc"
+	Fields string `json:"fields" url:"fields"` // comma separated fields to return
+	Tree   bool   `json:"tree" url:"tree"`     // return all categories nested in their parents, pagination is ignored
+}
+
+// Pagination - returns the pagination options of the listing.
+//
+//	@return *pagination.Options
+func (o *ListOptions) Pagination() *pagination.Options {
+	return &pagination.Options{
+		Limit:  o.Limit,
+		Page:   o.Page,
+		Cursor: o.Cursor,
+		Sort:   o.Sort,
+		Order:  o.Order,
+		Fields: o.Fields,
+	}
+}
+
+type CategoriesResponse struct {
+	Categories []Category `json:"data"`
 }
 
 type PaginatedCategoriesResponse struct {
 	Categories  []Category `json:"data"`
-	Total       int        `json:"total" db:"total"`
-	TotalPages  int        `json:"totalPages" db:"total_pages"`
-	CurrentPage int        `json:"currentPage" db:"current_page"`
+	Total       int        `json:"total" db:"total"`              // page mode only
+	TotalPages  int        `json:"totalPages" db:"total_pages"`   // page mode only
+	CurrentPage int        `json:"currentPage" db:"current_page"` // page mode only
+	NextCursor  string     `json:"nextCursor" db:"next_cursor"`   // empty when there are no more categories
 }
 
 type MultiIdsPayload struct {
 	Ids []string `json:"ids" db:"ids"`
 }
+
+// Event - returns the category as it is published in category events.
+//
+//	@return events.Category
+func (c *Category) Event() events.Category {
+	return events.Category{
+		ID:          c.ID,
+		UserID:      c.UID,
+		Name:        c.Name,
+		Description: c.Description,
+		Color:       c.Color,
+		Icon:        c.Icon,
+		Position:    c.Position,
+		ParentID:    c.ParentID,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// Changes - returns the names of the fields that differ between two states of a category,
+// as the json keys of the event payload (events.Category).
+//
+//	@param before - *Category
+//	@param after - *Category
+//	@return []string
+func Changes(before, after *Category) []string {
+	changed := []string{}
+	if before.Name != after.Name {
+		changed = append(changed, "name")
+	}
+	if before.Description != after.Description {
+		changed = append(changed, "description")
+	}
+	if before.Color != after.Color {
+		changed = append(changed, "color")
+	}
+	if before.Icon != after.Icon {
+		changed = append(changed, "icon")
+	}
+	if before.Position != after.Position {
+		changed = append(changed, "position")
+	}
+	if (before.ParentID == nil) != (after.ParentID == nil) || (before.ParentID != nil && *before.ParentID != *after.ParentID) {
+		changed = append(changed, "parent_id")
+	}
+
+	return changed
+}
+
+// tree - nests categories in their parents, keeping their order.
+// Categories whose parent is not in the list are at the top level.
+//
+//	@param categories - []Category
+//	@return []Category - the top level categories
+func tree(categories []Category) []Category {
+	ids := map[string]bool{}
+	children := map[string][]int{}
+	roots := []int{}
+	for _, category := range categories {
+		ids[category.ID] = true
+	}
+	for i, category := range categories {
+		if category.ParentID != nil && ids[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], i)
+			continue
+		}
+		roots = append(roots, i)
+	}
+
+	var nest func(indexes []int) []Category
+	nest = func(indexes []int) []Category {
+		nested := make([]Category, 0, len(indexes))
+		for _, i := range indexes {
+			category := categories[i]
+			category.Children = nest(children[category.ID])
+			nested = append(nested, category)
+		}
+		return nested
+	}
+
+	return nest(roots)
+}
@@ -0,0 +1,193 @@
+package defaults
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/color"
+	"encore.app/pkg/database"
+	"encore.app/tasks/cs"
+)
+
+// get the service name
+var defaultsDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// List - List returns the default categories in order.
+//
+//	@param ctx - context.Context
+//	@return *DefaultCategoriesResponse
+//	@return error
+func List(ctx context.Context) (*DefaultCategoriesResponse, error) {
+	categories, err := list(ctx, defaultsDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DefaultCategoriesResponse{Categories: categories}, nil
+}
+
+// list - returns the default categories in order.
+//
+//	@param ctx - context.Context
+//	@param db - database connection or transaction
+//	@return []DefaultCategory
+//	@return error
+func list(ctx context.Context, db sqlx.ExtContext) ([]DefaultCategory, error) {
+	// query statement to be executed
+	query := "SELECT * FROM default_categories ORDER BY position ASC, name ASC"
+
+	categories := []DefaultCategory{}
+	if err := database.NamedSliceQuery(ctx, db, query, map[string]any{}, &categories); err != nil {
+		return nil, fmt.Errorf("selecting default categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+// Set - Set replaces the default categories, users who already have theirs keep them.
+//
+//	@param ctx - context.Context
+//	@param payload - *SetDefaultCategoriesPayload
+//	@return *DefaultCategoriesResponse
+//	@return error
+func Set(ctx context.Context, payload *SetDefaultCategoriesPayload) (*DefaultCategoriesResponse, error) {
+	now := time.Now().UTC()
+
+	// normalize the categories like user categories
+	categories := []DefaultCategory{}
+	names := map[string]bool{}
+	for i, c := range payload.Categories {
+		category := DefaultCategory{
+			Name:        strings.ToLower(strings.TrimSpace(c.Name)),
+			Description: c.Description,
+			Color:       color.DefaultCategory,
+			Icon:        strings.TrimSpace(c.Icon),
+			Position:    i,
+			UpdatedAt:   now,
+		}
+		if len(category.Name) < 1 || names[category.Name] {
+			return nil, ErrDuplicateName
+		}
+		if category.Name == reservedName {
+			return nil, ErrReservedName
+		}
+		if len(strings.TrimSpace(c.Color)) > 0 {
+			var err error
+			if category.Color, err = color.Normalize(c.Color); err != nil {
+				return nil, err
+			}
+		}
+		names[category.Name] = true
+		categories = append(categories, category)
+	}
+
+	err := database.Transaction(ctx, defaultsDatabase, func(tx *sqlx.Tx) error {
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM default_categories", map[string]any{}); err != nil {
+			return fmt.Errorf("deleting default categories: %w", err)
+		}
+
+		// query statement to be executed
+		query := `
+      INSERT INTO default_categories (name, description, color, icon, position, updated_at)
+      VALUES (:name, :description, :color, :icon, :position, :updated_at)
+    `
+
+		for i := range categories {
+			if err := database.NamedExecQuery(ctx, tx, query, categories[i]); err != nil {
+				return fmt.Errorf("inserting default category: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DefaultCategoriesResponse{Categories: categories}, nil
+}
+
+// Seed - Seed creates the default categories of a user once, later calls create nothing.
+// Categories the user already has a category with the name of are skipped.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *SeedResponse
+//	@return error
+func Seed(ctx context.Context, uid string) (*SeedResponse, error) {
+	response := &SeedResponse{Categories: []cs.Category{}}
+
+	err := database.Transaction(ctx, defaultsDatabase, func(tx *sqlx.Tx) error {
+		now := time.Now().UTC()
+
+		// mark the user as seeded, nothing is created when they already are
+		var seed struct {
+			UID string `db:"uid"`
+		}
+		if err := database.NamedStructQuery(ctx, tx, `
+      INSERT INTO category_seeds (uid, seeded_at) VALUES (:uid, :now)
+      ON CONFLICT (uid) DO NOTHING
+      RETURNING uid
+    `, map[string]any{"uid": uid, "now": now}, &seed); err != nil {
+			if err == database.ErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("inserting category seed: %w", err)
+		}
+		response.Seeded = true
+
+		defaults, err := list(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// the categories the user already has, the defaults come after them
+		var existing []cs.Category
+		if err := database.NamedSliceQuery(ctx, tx, "SELECT * FROM categories WHERE uid = :uid", map[string]any{"uid": uid}, &existing); err != nil {
+			return fmt.Errorf("selecting categories: %w", err)
+		}
+		names := map[string]bool{}
+		position := 0
+		for _, category := range existing {
+			names[category.Name] = true
+			if category.Position >= position {
+				position = category.Position + 1
+			}
+		}
+
+		for _, d := range defaults {
+			if names[d.Name] {
+				continue
+			}
+			category := cs.Category{
+				ID:          uuid.New().String(),
+				UID:         uid,
+				Name:        d.Name,
+				Description: d.Description,
+				Color:       d.Color,
+				Icon:        d.Icon,
+				Position:    position,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			if err := cs.Insert(ctx, tx, &category); err != nil {
+				return err
+			}
+			position++
+			response.Categories = append(response.Categories, category)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,10 @@
+package defaults
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrDuplicateName - the default set has two categories with the name
+	ErrDuplicateName = &errs.Error{Code: errs.InvalidArgument, Message: "default categories must have different names"}
+	// ErrReservedName - the name is used by the calendar of uncategorized tasks
+	ErrReservedName = &errs.Error{Code: errs.InvalidArgument, Message: "\"general\" is reserved for uncategorized tasks"}
+)
@@ -0,0 +1,46 @@
+package defaults
+
+import (
+	"time"
+
+	"encore.app/tasks/cs"
+)
+
+// MaxCategories - the most default categories there can be
+const MaxCategories = 50
+
+// reservedName - the CalDAV calendar of uncategorized tasks and the category of legacy tasks
+const reservedName = "general"
+
+// DefaultCategory - a category new users start with
+type DefaultCategory struct {
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Color       string    `json:"color" db:"color"`       // a palette color name or "#rrggbb"
+	Icon        string    `json:"icon" db:"icon"`         // an emoji or icon name, empty -> no icon
+	Position    int       `json:"position" db:"position"` // the order of the categories, from 0
+	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// DefaultCategoryPayload - a category of the default set
+type DefaultCategoryPayload struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description" validate:"omitempty"`
+	Color       string `json:"color" validate:"omitempty"`       // a palette color, hex or rgb(r, g, b), default: "#00b3ff"
+	Icon        string `json:"icon" validate:"omitempty,max=32"` // an emoji or icon name
+}
+
+// SetDefaultCategoriesPayload - the new default set, in order
+type SetDefaultCategoriesPayload struct {
+	Categories []DefaultCategoryPayload `json:"categories" validate:"max=50,dive"`
+}
+
+type DefaultCategoriesResponse struct {
+	Categories []DefaultCategory `json:"data"`
+}
+
+// SeedResponse - the categories created for a user
+type SeedResponse struct {
+	Seeded     bool          `json:"seeded"` // false when the user already had the default categories created
+	Categories []cs.Category `json:"data"`
+}
@@ -0,0 +1,501 @@
+package delta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/color"
+	"encore.app/pkg/database"
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// get the service name
+var deltaDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// Changes - Changes returns the tasks and categories of a user changed since a token, and the tombstones
+// of the deleted ones. A change may be sent twice, clients apply them as upserts.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param encoded - the token of the last sync, empty for a full sync
+//	@param limit - the number of changes of the page
+//	@return *SyncResponse
+//	@return error
+func Changes(ctx context.Context, uid, encoded string, limit int) (*SyncResponse, error) {
+	t, err := decodeToken(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = defaultLimit
+	}
+
+	// the watermark is taken before reading, so changes committing meanwhile are sent by the next sync
+	watermark := t.Watermark
+	if !t.paging() {
+		if watermark, err = snapshotXmin(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// the position the page starts after
+	data := map[string]any{
+		"uid":       uid,
+		"since":     t.Since,
+		"txid":      int64(-1),
+		"kind":      "",
+		"entity_id": "00000000-0000-0000-0000-000000000000",
+		"limit":     limit + 1,
+	}
+	if t.paging() {
+		data["txid"], data["kind"], data["entity_id"] = t.TxID, t.Kind, t.EntityID
+	}
+
+	// query statement to be executed, a full sync has no use for tombstones
+	query := `
+    SELECT kind, entity_id, txid, deleted, changed_at FROM sync_log
+    WHERE uid = :uid AND txid >= :since AND (:since > 0 OR NOT deleted)
+      AND (txid, kind, entity_id) > (:txid, :kind, CAST(:entity_id AS UUID))
+    ORDER BY txid ASC, kind ASC, entity_id ASC
+    LIMIT :limit
+  `
+
+	var changes []change
+	if err := database.NamedSliceQuery(ctx, deltaDatabase, query, data, &changes); err != nil {
+		return nil, fmt.Errorf("selecting changes: %w", err)
+	}
+
+	response := &SyncResponse{Tasks: []ts.Task{}, Categories: []cs.Category{}, Deleted: []Tombstone{}}
+	if response.HasMore = len(changes) > limit; response.HasMore {
+		changes = changes[:limit]
+	}
+
+	if len(changes) > 0 {
+		last := changes[len(changes)-1]
+		data["end_txid"], data["end_kind"], data["end_entity_id"] = last.TxID, last.Kind, last.EntityID
+
+		for _, c := range changes {
+			if c.Deleted {
+				response.Deleted = append(response.Deleted, Tombstone{Kind: c.Kind, ID: c.EntityID, DeletedAt: c.ChangedAt})
+			}
+		}
+
+		// the rows changed in the page, rows changed again since are in a later page
+		rows := `
+      FROM %v r JOIN sync_log l ON l.uid = r.uid AND l.kind = '%v' AND l.entity_id = r.id
+      WHERE l.uid = :uid AND l.txid >= :since AND NOT l.deleted
+        AND (l.txid, l.kind, l.entity_id) > (:txid, :kind, CAST(:entity_id AS UUID))
+        AND (l.txid, l.kind, l.entity_id) <= (:end_txid, :end_kind, CAST(:end_entity_id AS UUID))
+      ORDER BY l.txid ASC, r.id ASC
+    `
+		if err := database.NamedSliceQuery(ctx, deltaDatabase, "SELECT r.* "+fmt.Sprintf(rows, "tasks", KindTask), data, &response.Tasks); err != nil {
+			return nil, fmt.Errorf("selecting changed tasks: %w", err)
+		}
+		if err := database.NamedSliceQuery(ctx, deltaDatabase, "SELECT r.* "+fmt.Sprintf(rows, "categories", KindCategory), data, &response.Categories); err != nil {
+			return nil, fmt.Errorf("selecting changed categories: %w", err)
+		}
+	}
+
+	// the token of the next page, or of the next sync
+	next := &token{Since: watermark}
+	if response.HasMore {
+		last := changes[len(changes)-1]
+		next = &token{Since: t.Since, Watermark: watermark, TxID: last.TxID, Kind: last.Kind, EntityID: last.EntityID}
+	}
+	response.Token = next.encode()
+
+	return response, nil
+}
+
+// Apply - Apply saves the mutations of a user in order, each in its own transaction.
+// Mutations that are not applied are returned with the reason and the server state.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param mutations - []Mutation
+//	@return []Result
+//	@return error
+func Apply(ctx context.Context, uid string, mutations []Mutation) ([]Result, error) {
+	results := []Result{}
+	now := time.Now().UTC()
+
+	for i := range mutations {
+		m := &mutations[i]
+		result := Result{ID: m.ID, Kind: m.Kind, Op: m.Op, EntityID: m.EntityID}
+
+		// changes from the future are made now, a skewed clock cannot win every conflict
+		at, err := time.Parse(time.RFC3339, m.ChangedAt)
+		if err != nil {
+			result.Status, result.Reason = StatusRejected, ReasonInvalid
+			results = append(results, result)
+			continue
+		}
+		at = at.UTC()
+		if at.After(now) {
+			at = now
+		}
+
+		err = database.Transaction(ctx, deltaDatabase, func(tx *sqlx.Tx) error {
+			if m.Kind == KindCategory {
+				return applyCategory(ctx, tx, uid, m, at, &result)
+			}
+			return applyTask(ctx, tx, uid, m, at, &result)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// applyTask - applies a task mutation.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param uid - string
+//	@param m - *Mutation
+//	@param at - when the change was made
+//	@param result - *Result
+//	@return error
+func applyTask(ctx context.Context, tx *sqlx.Tx, uid string, m *Mutation, at time.Time, result *Result) error {
+	var task ts.Task
+	exists, deleted, err := lock(ctx, tx, "tasks", uid, KindTask, m.EntityID, &task)
+	if err != nil {
+		return err
+	}
+	if exists && task.UserID != uid {
+		result.Status, result.Reason = StatusRejected, ReasonForbidden
+		return nil
+	}
+
+	switch {
+	case m.Op == OpDelete && !exists:
+		// deleting twice is fine
+		result.Status = StatusApplied
+	case m.Op == OpDelete && task.UpdatedAt.After(at):
+		result.Status, result.Reason, result.Task = StatusConflict, ReasonModified, &task
+	case m.Op == OpDelete:
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM tasks WHERE id = :id AND uid = :uid", task); err != nil {
+			return fmt.Errorf("deleting task: %w", err)
+		}
+		result.Status, result.previousTask = StatusApplied, &task
+	case deleted:
+		result.Status, result.Reason = StatusConflict, ReasonDeleted
+	case m.Task == nil:
+		result.Status, result.Reason = StatusRejected, ReasonInvalid
+	case !exists && m.Op == OpUpdate:
+		result.Status, result.Reason = StatusRejected, ReasonNotFound
+	case !exists:
+		task = ts.Task{
+			ID:             m.EntityID,
+			UserID:         uid,
+			Status:         "pending",
+			Color:          "default",
+			Priority:       ts.PriorityNone,
+			PinnedAt:       at,
+			PinnedPosition: -1,
+			ArchivedAt:     at,
+			CompletedAt:    at,
+			CreatedAt:      at,
+		}
+		if err := m.Task.apply(&task, at); err != nil || len(task.Title) < 1 {
+			result.Status, result.Reason = StatusRejected, ReasonInvalid
+			return nil
+		}
+		task.UpdatedAt = at
+		if err := ts.Insert(ctx, tx, &task); err != nil {
+			return err
+		}
+		result.Status, result.Task = StatusApplied, &task
+	case task.UpdatedAt.After(at):
+		// retried creates are updates, the last writer wins
+		result.Status, result.Reason, result.Task = StatusConflict, ReasonStale, &task
+	default:
+		previous := task
+		if err := m.Task.apply(&task, at); err != nil {
+			result.Status, result.Reason = StatusRejected, ReasonInvalid
+			return nil
+		}
+		task.UpdatedAt = at
+		if err := ts.Replace(ctx, tx, &task); err != nil {
+			return err
+		}
+		result.Status, result.Task, result.previousTask = StatusApplied, &task, &previous
+	}
+
+	return nil
+}
+
+// applyCategory - applies a category mutation.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param uid - string
+//	@param m - *Mutation
+//	@param at - when the change was made
+//	@param result - *Result
+//	@return error
+func applyCategory(ctx context.Context, tx *sqlx.Tx, uid string, m *Mutation, at time.Time, result *Result) error {
+	var category cs.Category
+	exists, deleted, err := lock(ctx, tx, "categories", uid, KindCategory, m.EntityID, &category)
+	if err != nil {
+		return err
+	}
+	if exists && category.UID != uid {
+		result.Status, result.Reason = StatusRejected, ReasonForbidden
+		return nil
+	}
+
+	switch {
+	case m.Op == OpDelete && !exists:
+		// deleting twice is fine
+		result.Status = StatusApplied
+	case m.Op == OpDelete && category.UpdatedAt.After(at):
+		result.Status, result.Reason, result.Category = StatusConflict, ReasonModified, &category
+	case m.Op == OpDelete:
+		if err := database.NamedExecQuery(ctx, tx, "DELETE FROM categories WHERE id = :id AND uid = :uid", category); err != nil {
+			return fmt.Errorf("deleting category: %w", err)
+		}
+		result.Status, result.previousCategory = StatusApplied, &category
+	case deleted:
+		result.Status, result.Reason = StatusConflict, ReasonDeleted
+	case m.Category == nil:
+		result.Status, result.Reason = StatusRejected, ReasonInvalid
+	case !exists && m.Op == OpUpdate:
+		result.Status, result.Reason = StatusRejected, ReasonNotFound
+	case !exists:
+		category = cs.Category{ID: m.EntityID, UID: uid, CreatedAt: at, UpdatedAt: at}
+		if err := m.Category.apply(&category); err != nil || len(category.Name) < 1 {
+			result.Status, result.Reason = StatusRejected, ReasonInvalid
+			return nil
+		}
+		if taken, err := nameTaken(ctx, tx, &category); err != nil || taken {
+			result.Status, result.Reason = StatusRejected, ReasonDuplicate
+			return err
+		}
+		if err := cs.Insert(ctx, tx, &category); err != nil {
+			if invalidParent(err) {
+				result.Status, result.Reason = StatusRejected, ReasonInvalid
+				return nil
+			}
+			return err
+		}
+		result.Status, result.Category = StatusApplied, &category
+	case category.UpdatedAt.After(at):
+		// retried creates are updates, the last writer wins
+		result.Status, result.Reason, result.Category = StatusConflict, ReasonStale, &category
+	default:
+		previous := category
+		if err := m.Category.apply(&category); err != nil {
+			result.Status, result.Reason = StatusRejected, ReasonInvalid
+			return nil
+		}
+		category.UpdatedAt = at
+		if taken, err := nameTaken(ctx, tx, &category); err != nil || taken {
+			result.Status, result.Reason = StatusRejected, ReasonDuplicate
+			return err
+		}
+		if err := cs.Replace(ctx, tx, &category); err != nil {
+			if invalidParent(err) {
+				result.Status, result.Reason = StatusRejected, ReasonInvalid
+				return nil
+			}
+			return err
+		}
+		result.Status, result.Category, result.previousCategory = StatusApplied, &category, &previous
+	}
+
+	return nil
+}
+
+// lock - selects a row for update, and whether it was deleted when it does not exist.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param table - string
+//	@param uid - string
+//	@param kind - string
+//	@param id - string
+//	@param dest - the row
+//	@return exists - bool
+//	@return deleted - bool
+//	@return err - error
+func lock(ctx context.Context, tx *sqlx.Tx, table, uid, kind, id string, dest any) (exists, deleted bool, err error) {
+	query := fmt.Sprintf("SELECT * FROM %v WHERE id = :id FOR UPDATE", table)
+	err = database.NamedStructQuery(ctx, tx, query, map[string]any{"id": id}, dest)
+	if err == nil {
+		return true, false, nil
+	}
+	if err != database.ErrNotFound {
+		return false, false, fmt.Errorf("selecting %v: %w", kind, err)
+	}
+
+	var tombstones []change
+	if err := database.NamedSliceQuery(ctx, tx, `
+    SELECT kind, entity_id, txid, deleted, changed_at FROM sync_log
+    WHERE uid = :uid AND kind = :kind AND entity_id = :id AND deleted
+  `, map[string]any{"uid": uid, "kind": kind, "id": id}, &tombstones); err != nil {
+		return false, false, fmt.Errorf("selecting tombstone: %w", err)
+	}
+
+	return false, len(tombstones) > 0, nil
+}
+
+// snapshotXmin - returns the id of the oldest transaction still running.
+//
+//	@param ctx - context.Context
+//	@return int64
+//	@return error
+func snapshotXmin(ctx context.Context) (int64, error) {
+	var row struct {
+		Xmin int64 `db:"xmin"`
+	}
+	if err := database.NamedStructQuery(ctx, deltaDatabase, "SELECT txid_snapshot_xmin(txid_current_snapshot()) AS xmin", map[string]any{}, &row); err != nil {
+		return 0, fmt.Errorf("selecting snapshot: %w", err)
+	}
+
+	return row.Xmin, nil
+}
+
+// apply - sets the fields of a task mutation.
+//
+//	@param task - *ts.Task
+//	@param at - when the change was made
+//	@return error
+func (f *TaskFields) apply(task *ts.Task, at time.Time) error {
+	if f.Title != nil {
+		task.Title = strings.TrimSpace(*f.Title)
+		if len(task.Title) < 1 {
+			return fmt.Errorf("empty title")
+		}
+	}
+	if f.Description != nil {
+		task.Description = *f.Description
+	}
+	// the category is resolved from the id, or else the name, when the task is saved
+	if f.Category != nil {
+		task.CategoryID, task.Category = nil, strings.ToLower(strings.TrimSpace(*f.Category))
+	}
+	if f.CategoryID != nil {
+		task.CategoryID, task.Category = nil, ""
+		if len(*f.CategoryID) > 0 {
+			id := *f.CategoryID
+			task.CategoryID = &id
+		}
+	}
+	if f.Priority != nil {
+		task.Priority = *f.Priority
+	}
+	if f.Color != nil {
+		task.Color = color.Default
+		if len(strings.TrimSpace(*f.Color)) > 0 {
+			c, err := color.Normalize(*f.Color)
+			if err != nil {
+				return err
+			}
+			task.Color = c
+		}
+	}
+	if f.Recurrence != nil {
+		task.Recurrence = strings.TrimSpace(*f.Recurrence)
+	}
+	if f.DueAt != nil {
+		task.DueAt = nil
+		if len(*f.DueAt) > 0 {
+			dueAt, err := time.Parse(time.RFC3339, *f.DueAt)
+			if err != nil {
+				return err
+			}
+			dueAt = dueAt.UTC()
+			task.DueAt = &dueAt
+		}
+	}
+	if f.Completed != nil && *f.Completed != task.Completed {
+		task.Completed, task.CompletedAt = *f.Completed, at
+	}
+	if f.Archived != nil && *f.Archived != task.Archived {
+		task.Archived, task.ArchivedAt = *f.Archived, at
+	}
+	if f.Pinned != nil && *f.Pinned != task.Pinned {
+		task.Pinned, task.PinnedAt = *f.Pinned, at
+	}
+
+	return nil
+}
+
+// apply - sets the fields of a category mutation.
+//
+//	@param category - *cs.Category
+//	@return error
+func (f *CategoryFields) apply(category *cs.Category) error {
+	if f.Name != nil {
+		category.Name = strings.ToLower(strings.TrimSpace(*f.Name))
+		if len(category.Name) < 1 {
+			return fmt.Errorf("empty name")
+		}
+	}
+	if f.Description != nil {
+		category.Description = *f.Description
+	}
+	if f.Color != nil {
+		category.Color = color.DefaultCategory
+		if len(strings.TrimSpace(*f.Color)) > 0 {
+			c, err := color.Normalize(*f.Color)
+			if err != nil {
+				return err
+			}
+			category.Color = c
+		}
+	}
+	if f.Icon != nil {
+		category.Icon = strings.TrimSpace(*f.Icon)
+	}
+	if f.Position != nil {
+		category.Position = *f.Position
+	}
+	if f.ParentID != nil {
+		category.ParentID = nil
+		if len(*f.ParentID) > 0 {
+			parentID := *f.ParentID
+			category.ParentID = &parentID
+		}
+	}
+
+	return nil
+}
+
+// nameTaken - reports whether another category of the user has the name of the category.
+// The unique names are checked first, a failed insert would abort the transaction of the other mutations.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param category - *cs.Category
+//	@return bool
+//	@return error
+func nameTaken(ctx context.Context, tx *sqlx.Tx, category *cs.Category) (bool, error) {
+	count, err := database.NamedCountQuery(ctx, tx, `
+    SELECT COUNT(*) FROM categories WHERE uid = :uid AND LOWER(name) = LOWER(:name) AND id <> :id
+  `, category)
+	if err != nil {
+		return false, fmt.Errorf("counting categories: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// invalidParent - reports whether a category was not saved because it cannot be nested in its parent.
+//
+//	@param err - error
+//	@return bool
+func invalidParent(err error) bool {
+	return err == cs.ErrParentNotFound || err == cs.ErrCycle || err == cs.ErrTooDeep
+}
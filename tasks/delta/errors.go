@@ -0,0 +1,8 @@
+package delta
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrInvalidToken - the sync token was not issued by the server, clients sync again without a token
+	ErrInvalidToken = &errs.Error{Code: errs.InvalidArgument, Message: "invalid sync token"}
+)
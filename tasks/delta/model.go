@@ -0,0 +1,166 @@
+package delta
+
+import (
+	"time"
+
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// the kinds of synced entities
+const (
+	KindTask     = "task"
+	KindCategory = "category"
+)
+
+// the operations of mutations
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// the statuses of mutation results
+const (
+	StatusApplied  = "applied"  // the change was saved
+	StatusConflict = "conflict" // the server state won, it is returned in the result
+	StatusRejected = "rejected" // the mutation is invalid, e.g. the entity belongs to another user
+)
+
+// the reasons of conflicts
+const (
+	ReasonDeleted  = "deleted"  // the entity was deleted on the server, deletes win over updates
+	ReasonStale    = "stale"    // the server state is newer than the change (last writer wins)
+	ReasonModified = "modified" // the entity changed on the server after the client deleted it, the edit wins
+)
+
+// the reasons of rejections
+const (
+	ReasonInvalid   = "invalid"   // the fields are missing or invalid
+	ReasonNotFound  = "not found" // the entity never existed
+	ReasonForbidden = "forbidden" // the entity belongs to another user
+	ReasonDuplicate = "duplicate" // another category of the user has the name
+)
+
+const (
+	// defaultLimit - the number of changes of a sync page
+	defaultLimit = 200
+)
+
+// SyncOptions - pulls the changes since a token
+type SyncOptions struct {
+	Token string `json:"token"`                                     // empty for a full sync
+	Limit int    `json:"limit" validate:"omitempty,min=1,max=1000"` // changes per page, default: 200
+}
+
+// SyncPayload - pushes mutations made offline and pulls the changes since a token
+type SyncPayload struct {
+	Token     string     `json:"token"`                                     // the token the mutations were made on, empty for a full sync
+	Limit     int        `json:"limit" validate:"omitempty,min=1,max=1000"` // changes per page, default: 200
+	Mutations []Mutation `json:"mutations" validate:"max=500,dive"`         // applied in order
+}
+
+// Mutation - a change made on a client
+// Conflicts are resolved per entity: deletes win over updates, an update is only applied
+// when it was made after the last change on the server (last writer wins), and a delete is
+// only applied when the entity did not change on the server after it was made.
+type Mutation struct {
+	ID        string          `json:"id" validate:"required,max=64"` // the client's id of the mutation, returned in its result
+	Kind      string          `json:"kind" validate:"required,oneof=task category"`
+	Op        string          `json:"op" validate:"required,oneof=create update delete"`
+	EntityID  string          `json:"entityId" validate:"required,uuid"`                                // generated by the client for creates
+	ChangedAt string          `json:"changedAt" validate:"required,datetime=2006-01-02T15:04:05Z07:00"` // when the change was made, RFC 3339
+	Task      *TaskFields     `json:"task" validate:"omitempty"`                                        // the changed fields of a task
+	Category  *CategoryFields `json:"category" validate:"omitempty"`                                    // the changed fields of a category
+}
+
+// TaskFields - the fields of a task mutation, missing fields are left unchanged
+type TaskFields struct {
+	Title       *string `json:"title" validate:"omitempty,min=1"`
+	Description *string `json:"description"`
+	CategoryID  *string `json:"categoryId" validate:"omitempty,uuid|eq="` // one of the user's categories, empty to remove the category
+	Category    *string `json:"category"`                                 // the name of one of the user's categories when no id is given
+	Priority    *string `json:"priority" validate:"omitempty,oneof=none low medium high"`
+	Color       *string `json:"color"`
+	DueAt       *string `json:"dueAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00|eq="` // RFC 3339, empty to remove the due date
+	Recurrence  *string `json:"recurrence"`
+	Completed   *bool   `json:"completed"`
+	Archived    *bool   `json:"archived"`
+	Pinned      *bool   `json:"pinned"`
+}
+
+// CategoryFields - the fields of a category mutation, missing fields are left unchanged
+type CategoryFields struct {
+	Name        *string `json:"name" validate:"omitempty,min=1"`
+	Description *string `json:"description"`
+	Color       *string `json:"color"`
+	Icon        *string `json:"icon" validate:"omitempty,max=32"`
+	Position    *int    `json:"position" validate:"omitempty,min=0"`
+	ParentID    *string `json:"parentId" validate:"omitempty,uuid|eq="` // empty to move the category to the top level
+}
+
+// Result - the outcome of a mutation
+type Result struct {
+	ID       string       `json:"id"`
+	Kind     string       `json:"kind"`
+	Op       string       `json:"op"`
+	EntityID string       `json:"entityId"`
+	Status   string       `json:"status"`             // applied, conflict, rejected
+	Reason   string       `json:"reason,omitempty"`   // why a mutation was not applied
+	Task     *ts.Task     `json:"task,omitempty"`     // the task on the server after the mutation
+	Category *cs.Category `json:"category,omitempty"` // the category on the server after the mutation
+
+	previousTask     *ts.Task     // the task before the mutation
+	previousCategory *cs.Category // the category before the mutation
+}
+
+// PreviousTask - returns the task before an applied mutation, nil for creates.
+//
+//	@return *ts.Task
+func (r *Result) PreviousTask() *ts.Task {
+	return r.previousTask
+}
+
+// PreviousCategory - returns the category before an applied mutation, nil for creates.
+//
+//	@return *cs.Category
+func (r *Result) PreviousCategory() *cs.Category {
+	return r.previousCategory
+}
+
+// Tombstone - a deleted task or category
+type Tombstone struct {
+	Kind      string    `json:"kind"`
+	ID        string    `json:"id"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// SyncResponse - the changes since a token
+type SyncResponse struct {
+	Tasks      []ts.Task     `json:"tasks"`      // created or updated
+	Categories []cs.Category `json:"categories"` // created or updated
+	Deleted    []Tombstone   `json:"deleted"`
+	Results    []Result      `json:"results,omitempty"` // the results of the pushed mutations, in order
+	Token      string        `json:"token"`             // the token of the next sync
+	HasMore    bool          `json:"hasMore"`           // sync again with the token right away for the next page
+}
+
+// token - the position of a client in the change log
+// Changes are ordered by the id of the transaction that made them. A transaction still running when
+// a page is read gets an id from the watermark on, so it is sent by a later sync rather than missed.
+type token struct {
+	Since     int64  `json:"s"`           // changes made by transactions from this id on
+	Watermark int64  `json:"w,omitempty"` // the since of the next sync, set while paging
+	TxID      int64  `json:"t,omitempty"` // the last change sent while paging
+	Kind      string `json:"k,omitempty"`
+	EntityID  string `json:"i,omitempty"`
+}
+
+// change - a row of the change log
+type change struct {
+	Kind      string    `db:"kind"`
+	EntityID  string    `db:"entity_id"`
+	TxID      int64     `db:"txid"`
+	Deleted   bool      `db:"deleted"`
+	ChangedAt time.Time `db:"changed_at"`
+}
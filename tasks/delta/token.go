@@ -0,0 +1,50 @@
+package delta
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// encode - returns the opaque form of a token.
+//
+//	@return string
+func (t *token) encode() string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// paging - reports whether the token continues a sync that has more pages.
+//
+//	@return bool
+func (t *token) paging() bool {
+	return len(t.Kind) > 0
+}
+
+// decodeToken - returns the token of its opaque form, the zero token for a full sync when it is empty.
+//
+//	@param s - string
+//	@return *token
+//	@return error
+func decodeToken(s string) (*token, error) {
+	t := &token{}
+	if len(s) < 1 {
+		return t, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := json.Unmarshal(b, t); err != nil || t.Since < 0 || t.Watermark < 0 {
+		return nil, ErrInvalidToken
+	}
+	if t.paging() {
+		if _, err := uuid.Parse(t.EntityID); err != nil || (t.Kind != KindTask && t.Kind != KindCategory) {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,156 @@
+package feed
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+)
+
+// get the service name
+var feedsDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// Generate - Generate creates the feed token of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return response
+//	@return error
+func Generate(ctx context.Context, uid string) (*FeedTokenResponse, error) {
+	// check if the user already has a token
+	if _, err := findByUID(ctx, uid); err == nil {
+		return nil, ErrAlreadyExists
+	}
+
+	return Rotate(ctx, uid)
+}
+
+// Rotate - Rotate replaces the feed token of a user, the previous feed url stops working.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return response
+//	@return error
+func Rotate(ctx context.Context, uid string) (*FeedTokenResponse, error) {
+	// create a random token
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("generating feed token: %w", err)
+	}
+
+	feed := FeedToken{
+		UID:       uid,
+		TokenHash: hash(token),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO feed_tokens (uid, token_hash, created_at)
+    VALUES (:uid, :token_hash, :created_at)
+    ON CONFLICT (uid) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, feedsDatabase, query, feed); err != nil {
+		return nil, fmt.Errorf("saving feed token: %w", err)
+	}
+
+	return &FeedTokenResponse{
+		Token:     token,
+		Path:      fmt.Sprintf("/feeds/%v", token),
+		CreatedAt: feed.CreatedAt,
+	}, nil
+}
+
+// Revoke - Revoke deletes the feed token of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return error
+func Revoke(ctx context.Context, uid string) error {
+	// query statement to be executed
+	q := "DELETE FROM feed_tokens WHERE uid = :uid"
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, feedsDatabase, q, map[string]any{"uid": uid}); err != nil {
+		return fmt.Errorf("deleting feed token: %w", err)
+	}
+
+	return nil
+}
+
+// FindUserByToken - FindUserByToken returns the id of the user a feed token belongs to.
+//
+//	@param ctx - context.Context
+//	@param token - string
+//	@return string
+//	@return error
+func FindUserByToken(ctx context.Context, token string) (string, error) {
+	// query statement to be executed
+	q := "SELECT * FROM feed_tokens WHERE token_hash = :token_hash LIMIT 1"
+
+	// declare feed token
+	var feed FeedToken
+	// execute query
+	if err := database.NamedStructQuery(ctx, feedsDatabase, q, map[string]any{"token_hash": hash(token)}, &feed); err != nil {
+		if err == database.ErrNotFound {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("selecting feed token: %w", err)
+	}
+
+	return feed.UID, nil
+}
+
+// findByUID - gets the feed token of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return FeedToken
+//	@return error
+func findByUID(ctx context.Context, uid string) (FeedToken, error) {
+	// declare feed token
+	var feed FeedToken
+	// execute query
+	if err := database.NamedStructQuery(ctx, feedsDatabase, "SELECT * FROM feed_tokens WHERE uid = :uid LIMIT 1", map[string]any{
+		"uid": uid,
+	}, &feed); err != nil {
+		if err == database.ErrNotFound {
+			return FeedToken{}, ErrNotFound
+		}
+		return FeedToken{}, fmt.Errorf("selecting feed token: %w", err)
+	}
+
+	return feed, nil
+}
+
+// newToken - creates a random url safe token.
+//
+//	@return string
+//	@return error
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hash - returns the sha256 of a token, tokens are looked up by their hash.
+//
+//	@param token - string
+//	@return string
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,10 @@
+package feed
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrNotFound - the feed token does not exist or has been revoked
+	ErrNotFound = &errs.Error{Code: errs.NotFound, Message: "feed not found"}
+	// ErrAlreadyExists - the user already has a feed token, it can be rotated instead
+	ErrAlreadyExists = &errs.Error{Code: errs.AlreadyExists, Message: "feed token already exists"}
+)
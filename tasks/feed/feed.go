@@ -0,0 +1,138 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"encore.app/pkg/ical"
+	"encore.app/tasks/ts"
+)
+
+// prodID - identifies bookie as the creator of the calendars
+const prodID = "-//Bookie//Bookie API//EN"
+
+// Priorities - the iCalendar PRIORITY (1 highest, 9 lowest) of the task priorities
+var Priorities = map[string]int{
+	ts.PriorityHigh:   1,
+	ts.PriorityMedium: 5,
+	ts.PriorityLow:    9,
+}
+
+// Write - streams the tasks with a due date of a user as an iCalendar (RFC 5545).
+//
+//	@param ctx - context.Context
+//	@param w - io.Writer
+//	@param uid - string
+//	@param categories - only tasks in these categories, all when empty
+//	@param asEvents - write VEVENT components instead of VTODO
+//	@return error
+func Write(ctx context.Context, w io.Writer, uid string, categories []string, asEvents bool) error {
+	writer, err := ical.NewWriter(w, prodID, "Bookie")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	// write a component per task
+	if err := ts.StreamUserDueTasks(ctx, uid, categories, func(task ts.Task) error {
+		if asEvents {
+			return writer.WriteComponent(Event(task, now))
+		}
+		return writer.WriteComponent(Todo(task, now))
+	}); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Todo - returns the VTODO component of a task.
+//
+//	@param task - ts.Task
+//	@param stamp - the time the component is created
+//	@return *ical.Component
+func Todo(task ts.Task, stamp time.Time) *ical.Component {
+	c := &ical.Component{Name: "VTODO"}
+	c.Add("UID", uid(task))
+	c.Add("DTSTAMP", ical.DateTime(stamp))
+	c.Add("CREATED", ical.DateTime(task.CreatedAt))
+	c.Add("LAST-MODIFIED", ical.DateTime(task.UpdatedAt))
+	c.Add("SUMMARY", ical.Text(task.Title))
+
+	// optional properties
+	if len(task.Description) > 0 {
+		c.Add("DESCRIPTION", ical.Text(task.Description))
+	}
+	if len(task.Category) > 0 {
+		c.Add("CATEGORIES", ical.Text(task.Category))
+	}
+	if task.DueAt != nil {
+		c.Add("DUE", ical.DateTime(*task.DueAt))
+		if len(task.Recurrence) > 0 {
+			c.Add("RRULE", task.Recurrence)
+		}
+	}
+	if priority, ok := Priorities[task.Priority]; ok {
+		c.Add("PRIORITY", fmt.Sprint(priority))
+	}
+
+	// completion
+	if task.Completed {
+		c.Add("STATUS", "COMPLETED")
+		c.Add("COMPLETED", ical.DateTime(task.CompletedAt))
+		c.Add("PERCENT-COMPLETE", "100")
+	} else {
+		c.Add("STATUS", "NEEDS-ACTION")
+	}
+
+	return c
+}
+
+// Event - returns the VEVENT component of a task, starting at its due date.
+//
+//	@param task - ts.Task
+//	@param stamp - the time the component is created
+//	@return *ical.Component
+func Event(task ts.Task, stamp time.Time) *ical.Component {
+	c := &ical.Component{Name: "VEVENT"}
+	c.Add("UID", uid(task))
+	c.Add("DTSTAMP", ical.DateTime(stamp))
+	c.Add("CREATED", ical.DateTime(task.CreatedAt))
+	c.Add("LAST-MODIFIED", ical.DateTime(task.UpdatedAt))
+
+	// mark completed tasks in the title, events have no completion
+	summary := task.Title
+	if task.Completed {
+		summary = fmt.Sprintf("✓ %v", task.Title)
+	}
+	c.Add("SUMMARY", ical.Text(summary))
+
+	// optional properties
+	if len(task.Description) > 0 {
+		c.Add("DESCRIPTION", ical.Text(task.Description))
+	}
+	if len(task.Category) > 0 {
+		c.Add("CATEGORIES", ical.Text(task.Category))
+	}
+	if task.DueAt != nil {
+		c.Add("DTSTART", ical.DateTime(*task.DueAt))
+		c.Add("DTEND", ical.DateTime(*task.DueAt))
+		if len(task.Recurrence) > 0 {
+			c.Add("RRULE", task.Recurrence)
+		}
+	}
+	c.Add("TRANSP", "TRANSPARENT")
+
+	return c
+}
+
+// uid - returns the globally unique id of a task's component.
+//
+//	@param task - ts.Task
+//	@return string
+func uid(task ts.Task) string {
+	return fmt.Sprintf("%v@bookie", task.ID)
+}
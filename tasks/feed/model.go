@@ -0,0 +1,15 @@
+package feed
+
+import "time"
+
+type FeedToken struct {
+	UID       string    `json:"uid" db:"uid"`
+	TokenHash string    `json:"-" db:"token_hash"` // sha256 of the token, the token itself is never stored
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
+
+type FeedTokenResponse struct {
+	Token     string    `json:"token"` // only returned when the token is generated or rotated
+	Path      string    `json:"path"`  // the path of the feed, relative to the api base url
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,454 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+	"encore.app/pkg/pagination"
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// get the service name
+var filtersDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// sortColumns - the expressions tasks are sorted by
+var sortColumns = map[string]string{
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+	"dueAt":       "due_at",
+	"completedAt": "completed_at",
+	"priority":    "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END",
+	"title":       "title",
+}
+
+// Create - Create saves a filter of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *CreateFilterPayload
+//	@return *FilterResponse
+//	@return error
+func Create(ctx context.Context, uid string, payload *CreateFilterPayload) (*FilterResponse, error) {
+	filter := SavedFilter{
+		ID:        uuid.New().String(),
+		UserID:    uid,
+		Name:      strings.TrimSpace(payload.Name),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := filter.setCriteria(payload.Criteria); err != nil {
+		return nil, err
+	}
+	if err := checkName(ctx, uid, "", filter.Name); err != nil {
+		return nil, err
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO saved_filters (id, uid, name, criteria, created_at, updated_at)
+    VALUES (:id, :uid, :name, :criteria, :created_at, :updated_at)
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, filtersDatabase, query, filter); err != nil {
+		return nil, fmt.Errorf("inserting filter: %w", err)
+	}
+
+	return filter.Response()
+}
+
+// Get - Get returns a saved filter of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return *SavedFilter
+//	@return error
+func Get(ctx context.Context, uid, id string) (*SavedFilter, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	// query statement to be executed
+	query := "SELECT * FROM saved_filters WHERE id = :id AND uid = :uid"
+
+	var filter SavedFilter
+	if err := database.NamedStructQuery(ctx, filtersDatabase, query, map[string]any{"id": id, "uid": uid}, &filter); err != nil {
+		if err == database.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("selecting filter: %w", err)
+	}
+
+	return &filter, nil
+}
+
+// Find - Find returns a built-in list or a saved filter of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - the id of a saved filter or the name of a built-in list
+//	@return *FilterResponse
+//	@return error
+func Find(ctx context.Context, uid, id string) (*FilterResponse, error) {
+	for i := range builtIns {
+		if builtIns[i].ID == id {
+			filter := builtIns[i]
+			return &filter, nil
+		}
+	}
+
+	filter, err := Get(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.Response()
+}
+
+// List - List returns the built-in lists and the saved filters of a user by name.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *FiltersResponse
+//	@return error
+func List(ctx context.Context, uid string) (*FiltersResponse, error) {
+	// query statement to be executed
+	query := "SELECT * FROM saved_filters WHERE uid = :uid ORDER BY name ASC, id ASC"
+
+	var filters []SavedFilter
+	if err := database.NamedSliceQuery(ctx, filtersDatabase, query, map[string]any{"uid": uid}, &filters); err != nil {
+		return nil, fmt.Errorf("selecting filters: %w", err)
+	}
+
+	response := &FiltersResponse{Filters: append([]FilterResponse{}, builtIns...)}
+	for i := range filters {
+		filter, err := filters[i].Response()
+		if err != nil {
+			return nil, err
+		}
+		response.Filters = append(response.Filters, *filter)
+	}
+
+	return response, nil
+}
+
+// Update - Update renames a saved filter of a user or replaces its criteria.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param payload - *UpdateFilterPayload
+//	@return *FilterResponse
+//	@return error
+func Update(ctx context.Context, uid, id string, payload *UpdateFilterPayload) (*FilterResponse, error) {
+	if isBuiltIn(id) {
+		return nil, ErrBuiltIn
+	}
+
+	filter, err := Get(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(payload.Name); len(name) > 0 && name != filter.Name {
+		if err := checkName(ctx, uid, id, name); err != nil {
+			return nil, err
+		}
+		filter.Name = name
+	}
+	if payload.Criteria != nil {
+		if err := filter.setCriteria(*payload.Criteria); err != nil {
+			return nil, err
+		}
+	}
+	filter.UpdatedAt = time.Now().UTC()
+
+	// query statement to be executed
+	query := `
+    UPDATE saved_filters SET name = :name, criteria = :criteria, updated_at = :updated_at
+    WHERE id = :id AND uid = :uid
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, filtersDatabase, query, filter); err != nil {
+		return nil, fmt.Errorf("updating filter: %w", err)
+	}
+
+	return filter.Response()
+}
+
+// Delete - Delete removes a saved filter of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return error
+func Delete(ctx context.Context, uid, id string) error {
+	if isBuiltIn(id) {
+		return ErrBuiltIn
+	}
+	if _, err := Get(ctx, uid, id); err != nil {
+		return err
+	}
+
+	// query statement to be executed
+	query := "DELETE FROM saved_filters WHERE id = :id AND uid = :uid"
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, filtersDatabase, query, map[string]any{"id": id, "uid": uid}); err != nil {
+		return fmt.Errorf("deleting filter: %w", err)
+	}
+
+	return nil
+}
+
+// Evaluate - Evaluate returns a page of the tasks of a user matching a filter.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param filter - *FilterResponse
+//	@param options - *EvaluateOptions
+//	@param location - the timezone due dates are resolved in
+//	@return *EvaluateResponse
+//	@return error
+func Evaluate(ctx context.Context, uid string, filter *FilterResponse, options *EvaluateOptions, location *time.Location) (*EvaluateResponse, error) {
+	where, data := conditions(&filter.Criteria, time.Now().In(location))
+	data["uid"] = uid
+
+	// count the tasks
+	count, err := database.NamedCountQuery(ctx, filtersDatabase, "SELECT COUNT(*) FROM tasks WHERE "+where, data)
+	if err != nil {
+		return nil, fmt.Errorf("counting tasks: %w", err)
+	}
+
+	limit := options.Limit
+	if limit < 1 {
+		limit = 20
+	}
+	paging := pagination.New(options.Page, limit, count)
+	if options.Page > paging.Pages() {
+		paging.SetPage(paging.Pages())
+	}
+	data["limit"] = paging.PerPage()
+	data["offset"] = paging.Offset()
+
+	// query statement to be executed
+	query := fmt.Sprintf(`
+    SELECT * FROM tasks
+    WHERE %v
+    ORDER BY %v
+    LIMIT :limit OFFSET :offset
+  `, where, orderBy(&filter.Criteria))
+
+	tasks := []ts.Task{}
+	if err := database.NamedSliceQuery(ctx, filtersDatabase, query, data, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting tasks: %w", err)
+	}
+
+	return &EvaluateResponse{
+		Filter:      *filter,
+		Tasks:       tasks,
+		Total:       paging.Total(),
+		TotalPages:  paging.Pages(),
+		CurrentPage: paging.Page(),
+		Timezone:    location.String(),
+	}, nil
+}
+
+// conditions - returns the conditions of the criteria and their arguments.
+//
+//	@param criteria - *Criteria
+//	@param now - the current time in the timezone of the user
+//	@return string
+//	@return map[string]any
+func conditions(criteria *Criteria, now time.Time) (string, map[string]any) {
+	where := []string{"uid = :uid"}
+	data := map[string]any{"now": now.UTC()}
+
+	// filters of completed tasks list completed tasks unless another status is set
+	status := criteria.Status
+	if len(status) < 1 {
+		status = StatusOpen
+		if criteria.CompletedWithinDays > 0 {
+			status = StatusCompleted
+		}
+	}
+	switch status {
+	case StatusOpen:
+		where = append(where, "NOT completed AND NOT archived")
+	case StatusCompleted:
+		where = append(where, "completed")
+	case StatusArchived:
+		where = append(where, "archived")
+	}
+
+	if len(criteria.Categories) > 0 && criteria.IncludeSubcategories {
+		where = append(where, fmt.Sprintf(`category_id IN (
+      WITH RECURSIVE nested AS (
+        SELECT id, 1 AS level FROM categories WHERE uid = :uid AND name = ANY(:categories)
+        UNION ALL
+        SELECT categories.id, nested.level + 1 FROM categories JOIN nested ON categories.parent_id = nested.id
+        WHERE nested.level < %v
+      )
+      SELECT id FROM nested
+    )`, cs.MaxDepth))
+		data["categories"] = criteria.Categories
+	} else if len(criteria.Categories) > 0 {
+		where = append(where, "category = ANY(:categories)")
+		data["categories"] = criteria.Categories
+	}
+	if criteria.NoCategory {
+		where = append(where, "category_id IS NULL")
+	}
+	if len(criteria.Priorities) > 0 {
+		where = append(where, "priority = ANY(:priorities)")
+		data["priorities"] = criteria.Priorities
+	}
+
+	// the bounds of the local days
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	between := func(from, to time.Time) {
+		where = append(where, "due_at >= :due_from AND due_at < :due_to")
+		data["due_from"] = from.UTC()
+		data["due_to"] = to.UTC()
+	}
+	switch criteria.Due {
+	case DueOverdue:
+		where = append(where, "due_at < :now")
+	case DueToday:
+		between(today, today.AddDate(0, 0, 1))
+	case DueTomorrow:
+		between(today.AddDate(0, 0, 1), today.AddDate(0, 0, 2))
+	case DueThisWeek:
+		// weeks end on Sunday
+		between(today, today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7))
+	case DueNext7Days:
+		between(today, today.AddDate(0, 0, 7))
+	case DueUpcoming:
+		where = append(where, "due_at >= :due_from")
+		data["due_from"] = today.AddDate(0, 0, 1).UTC()
+	case DueNone:
+		where = append(where, "due_at IS NULL")
+	case DueAny:
+		where = append(where, "due_at IS NOT NULL")
+	}
+
+	if criteria.CompletedWithinDays > 0 {
+		if status != StatusCompleted {
+			where = append(where, "completed")
+		}
+		where = append(where, "completed_at >= :completed_since")
+		data["completed_since"] = now.AddDate(0, 0, -criteria.CompletedWithinDays).UTC()
+	}
+	if search := strings.TrimSpace(criteria.Search); len(search) > 0 {
+		where = append(where, `(title ILIKE :search ESCAPE '\' OR description ILIKE :search ESCAPE '\')`)
+		data["search"] = "%" + likeEscaper.Replace(search) + "%"
+	}
+	if !criteria.IncludeSnoozed {
+		where = append(where, "(snoozed_until IS NULL OR snoozed_until <= :now)")
+	}
+
+	return strings.Join(where, " AND "), data
+}
+
+// likeEscaper - escapes the wildcards of a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// orderBy - returns the sort order of the criteria, tasks without a due date come last.
+//
+//	@param criteria - *Criteria
+//	@return string
+func orderBy(criteria *Criteria) string {
+	column, ok := sortColumns[criteria.Sort]
+	if !ok {
+		column = sortColumns["createdAt"]
+	}
+	direction := "ASC"
+	if criteria.Order == "desc" {
+		direction = "DESC"
+	}
+
+	return fmt.Sprintf("%v %v NULLS LAST, id %v", column, direction, direction)
+}
+
+// checkName - returns ErrAlreadyExists when another filter of the user has the name.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - the filter being renamed, empty for a new filter
+//	@param name - string
+//	@return error
+func checkName(ctx context.Context, uid, id, name string) error {
+	// query statement to be executed
+	query := "SELECT COUNT(*) FROM saved_filters WHERE uid = :uid AND name = :name AND CAST(id AS TEXT) <> :id"
+
+	count, err := database.NamedCountQuery(ctx, filtersDatabase, query, map[string]any{"uid": uid, "name": name, "id": id})
+	if err != nil {
+		return fmt.Errorf("counting filters: %w", err)
+	}
+	if count > 0 {
+		return ErrAlreadyExists
+	}
+
+	return nil
+}
+
+// isBuiltIn - reports whether an id is the name of a built-in list.
+//
+//	@param id - string
+//	@return bool
+func isBuiltIn(id string) bool {
+	for i := range builtIns {
+		if builtIns[i].ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// setCriteria - stores the criteria of the filter as JSON, category names are compared in lower case.
+//
+//	@param criteria - Criteria
+//	@return error
+func (f *SavedFilter) setCriteria(criteria Criteria) error {
+	for i, category := range criteria.Categories {
+		criteria.Categories[i] = strings.ToLower(strings.TrimSpace(category))
+	}
+	criteria.Search = strings.TrimSpace(criteria.Search)
+
+	b, err := json.Marshal(criteria)
+	if err != nil {
+		return fmt.Errorf("encoding filter criteria: %w", err)
+	}
+	f.Criteria = string(b)
+
+	return nil
+}
+
+// Response - returns the filter with its decoded criteria.
+//
+//	@return *FilterResponse
+//	@return error
+func (f *SavedFilter) Response() (*FilterResponse, error) {
+	response := &FilterResponse{
+		ID:        f.ID,
+		Name:      f.Name,
+		CreatedAt: &f.CreatedAt,
+		UpdatedAt: &f.UpdatedAt,
+	}
+	if err := json.Unmarshal([]byte(f.Criteria), &response.Criteria); err != nil {
+		return nil, fmt.Errorf("decoding filter criteria: %w", err)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,12 @@
+package filters
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrNotFound - the filter does not exist or belongs to another user
+	ErrNotFound = &errs.Error{Code: errs.NotFound, Message: "filter not found"}
+	// ErrAlreadyExists - the user has a filter with the name
+	ErrAlreadyExists = &errs.Error{Code: errs.AlreadyExists, Message: "filter already exists"}
+	// ErrBuiltIn - built-in lists cannot be changed
+	ErrBuiltIn = &errs.Error{Code: errs.InvalidArgument, Message: "built-in lists cannot be changed"}
+)
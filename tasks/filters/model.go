@@ -0,0 +1,126 @@
+package filters
+
+import (
+	"time"
+
+	"encore.app/tasks/ts"
+)
+
+// the built-in lists, evaluated like saved filters with their ids
+const (
+	ListToday             = "today"
+	ListUpcoming          = "upcoming"
+	ListOverdue           = "overdue"
+	ListNoCategory        = "no-category"
+	ListRecentlyCompleted = "recently-completed"
+)
+
+// the states of the tasks a filter matches
+const (
+	StatusOpen      = "open" // neither completed nor archived
+	StatusCompleted = "completed"
+	StatusArchived  = "archived"
+	StatusAll       = "all"
+)
+
+// the due date ranges of a filter, resolved in the user's timezone when it is evaluated
+const (
+	DueOverdue   = "overdue"     // due before now
+	DueToday     = "today"       // due today
+	DueTomorrow  = "tomorrow"    // due tomorrow
+	DueThisWeek  = "this_week"   // due from today to the end of Sunday
+	DueNext7Days = "next_7_days" // due from today to the end of the sixth day after it
+	DueUpcoming  = "upcoming"    // due after today
+	DueNone      = "none"        // without a due date
+	DueAny       = "any"         // with a due date
+)
+
+// RecentDays - the days the recently completed list looks back
+const RecentDays = 7
+
+// Criteria - the conditions a task matches, the conditions that are set must all hold
+type Criteria struct {
+	// open, completed, archived, all, default: "open", "completed" with completedWithinDays
+	Status string `json:"status" validate:"omitempty,oneof=open completed archived all"`
+	// any of the categories, all categories when empty
+	Categories []string `json:"categories" validate:"max=50,dive,required,max=255"`
+	// the categories nested in the categories too
+	IncludeSubcategories bool `json:"includeSubcategories"`
+	// only tasks that are in none of the user's categories
+	NoCategory bool `json:"noCategory"`
+	// any of the priorities, all priorities when empty
+	Priorities []string `json:"priorities" validate:"dive,oneof=none low medium high"`
+	// overdue, today, tomorrow, this_week, next_7_days, upcoming, none, any
+	Due string `json:"due" validate:"omitempty,oneof=overdue today tomorrow this_week next_7_days upcoming none any"`
+	// only tasks completed in the last days
+	CompletedWithinDays int `json:"completedWithinDays" validate:"omitempty,min=1,max=365"`
+	// text in the title or description
+	Search string `json:"search" validate:"max=255"`
+	// list snoozed tasks too
+	IncludeSnoozed bool `json:"includeSnoozed"`
+	// createdAt, updatedAt, dueAt, completedAt, priority, title, default: "createdAt"
+	Sort string `json:"sort" validate:"omitempty,oneof=createdAt updatedAt dueAt completedAt priority title"`
+	// asc, desc, default: "asc"
+	Order string `json:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+// builtIns - the criteria of the built-in lists
+var builtIns = []FilterResponse{
+	{ID: ListToday, Name: "Today", BuiltIn: true, Criteria: Criteria{Due: DueToday, Sort: "dueAt"}},
+	{ID: ListUpcoming, Name: "Upcoming", BuiltIn: true, Criteria: Criteria{Due: DueUpcoming, Sort: "dueAt"}},
+	{ID: ListOverdue, Name: "Overdue", BuiltIn: true, Criteria: Criteria{Due: DueOverdue, Sort: "dueAt"}},
+	{ID: ListNoCategory, Name: "No category", BuiltIn: true, Criteria: Criteria{NoCategory: true, Order: "desc"}},
+	{
+		ID:       ListRecentlyCompleted,
+		Name:     "Recently completed",
+		BuiltIn:  true,
+		Criteria: Criteria{Status: StatusCompleted, CompletedWithinDays: RecentDays, Sort: "completedAt", Order: "desc"},
+	},
+}
+
+type SavedFilter struct {
+	ID        string    `json:"id" db:"id"`
+	UserID    string    `json:"uid" db:"uid"`
+	Name      string    `json:"name" db:"name"`
+	Criteria  string    `json:"-" db:"criteria"` // the criteria as JSON
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+type FilterResponse struct {
+	ID        string     `json:"id"` // the list name for built-in lists
+	Name      string     `json:"name"`
+	BuiltIn   bool       `json:"builtIn"`
+	Criteria  Criteria   `json:"criteria"`
+	CreatedAt *time.Time `json:"createdAt"` // nil for built-in lists
+	UpdatedAt *time.Time `json:"updatedAt"` // nil for built-in lists
+}
+
+type FiltersResponse struct {
+	Filters []FilterResponse `json:"data"` // the built-in lists first
+}
+
+type CreateFilterPayload struct {
+	Name     string   `json:"name" validate:"required,max=255"`
+	Criteria Criteria `json:"criteria"`
+}
+
+type UpdateFilterPayload struct {
+	Name     string    `json:"name" validate:"omitempty,max=255"`
+	Criteria *Criteria `json:"criteria" validate:"omitempty"` // replaces the criteria, unchanged when missing
+}
+
+type EvaluateOptions struct {
+	Page     int    `json:"page" validate:"omitempty,min=1"`
+	Limit    int    `json:"limit" validate:"omitempty,min=1,max=100"` // default: 20
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`   // default: the user's timezone
+}
+
+type EvaluateResponse struct {
+	Filter      FilterResponse `json:"filter"`
+	Tasks       []ts.Task      `json:"data"`
+	Total       int            `json:"total"`
+	TotalPages  int            `json:"totalPages"`
+	CurrentPage int            `json:"currentPage"`
+	Timezone    string         `json:"timezone"` // the timezone the due dates were resolved in
+}
@@ -0,0 +1,110 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+)
+
+// get the service name
+var liveDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// Publish - Publish saves an event of a user and wakes the streams of the user on this instance.
+// Streams on other instances read it on their next poll.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param name - the event name, e.g. "task.updated"
+//	@param data - the domain event
+//	@return error
+func Publish(ctx context.Context, uid, name string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding live event: %w", err)
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO live_events (uid, event, data, created_at)
+    VALUES (:uid, :event, :data, :created_at)
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, liveDatabase, query, Event{
+		UserID:    uid,
+		Name:      name,
+		Data:      string(body),
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("inserting live event: %w", err)
+	}
+
+	hub.notify(uid)
+	return nil
+}
+
+// Prune - Prune deletes the events older than the retention, they can no longer be resumed from.
+//
+//	@param ctx - context.Context
+//	@return error
+func Prune(ctx context.Context) error {
+	// query statement to be executed
+	query := "DELETE FROM live_events WHERE created_at < :before"
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, liveDatabase, query, map[string]any{"before": time.Now().Add(-Retention).UTC()}); err != nil {
+		return fmt.Errorf("deleting live events: %w", err)
+	}
+
+	return nil
+}
+
+// since - returns the events of a user after an id, the oldest first.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param after - the id of the last event the client received
+//	@return []Event
+//	@return error
+func since(ctx context.Context, uid string, after int64) ([]Event, error) {
+	// query statement to be executed
+	query := `
+    SELECT * FROM live_events
+    WHERE uid = :uid AND id > :after
+    ORDER BY id ASC
+    LIMIT :limit
+  `
+
+	events := []Event{}
+	if err := database.NamedSliceQuery(ctx, liveDatabase, query, map[string]any{
+		"uid":   uid,
+		"after": after,
+		"limit": batchSize,
+	}, &events); err != nil {
+		return nil, fmt.Errorf("selecting live events: %w", err)
+	}
+
+	return events, nil
+}
+
+// latest - returns the id of the last event, new streams start after it.
+//
+//	@param ctx - context.Context
+//	@return int64
+//	@return error
+func latest(ctx context.Context) (int64, error) {
+	var row struct {
+		ID int64 `db:"id"`
+	}
+	if err := database.NamedStructQuery(ctx, liveDatabase, "SELECT COALESCE(MAX(id), 0) AS id FROM live_events", map[string]any{}, &row); err != nil {
+		return 0, fmt.Errorf("selecting last live event: %w", err)
+	}
+
+	return row.ID, nil
+}
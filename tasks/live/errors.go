@@ -0,0 +1,10 @@
+package live
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrStreamingUnsupported - the response writer cannot flush events as they happen
+	ErrStreamingUnsupported = &errs.Error{Code: errs.Internal, Message: "streaming is not supported"}
+	// ErrInvalidLastEventID - the Last-Event-ID is not an id sent by the stream
+	ErrInvalidLastEventID = &errs.Error{Code: errs.InvalidArgument, Message: "invalid Last-Event-ID"}
+)
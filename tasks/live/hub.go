@@ -0,0 +1,53 @@
+package live
+
+import "sync"
+
+// hub - the streams open on this instance, by user
+var hub = &registry{streams: map[string]map[chan struct{}]struct{}{}}
+
+// registry - wakes the streams of a user when one of their events is published
+type registry struct {
+	mu      sync.Mutex
+	streams map[string]map[chan struct{}]struct{}
+}
+
+// subscribe - registers a stream of a user, the returned function unregisters it.
+//
+//	@param uid - string
+//	@return chan struct{} - receives when events may be waiting
+//	@return func()
+func (r *registry) subscribe(uid string) (chan struct{}, func()) {
+	// one pending wake up is enough, the stream reads every waiting event
+	ch := make(chan struct{}, 1)
+
+	r.mu.Lock()
+	if r.streams[uid] == nil {
+		r.streams[uid] = map[chan struct{}]struct{}{}
+	}
+	r.streams[uid][ch] = struct{}{}
+	r.mu.Unlock()
+
+	return ch, func() {
+		r.mu.Lock()
+		delete(r.streams[uid], ch)
+		if len(r.streams[uid]) == 0 {
+			delete(r.streams, uid)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// notify - wakes the streams of a user without blocking.
+//
+//	@param uid - string
+func (r *registry) notify(uid string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for ch := range r.streams[uid] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
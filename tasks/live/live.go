@@ -0,0 +1,120 @@
+package live
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"encore.dev/rlog"
+)
+
+// Serve - Serve streams the events of a user as Server-Sent Events until the client disconnects.
+// Clients resuming with a Last-Event-ID header (or lastEventId query parameter) first receive
+// the events they missed, new clients only receive events published after they connected.
+//
+//	@param w - http.ResponseWriter
+//	@param req - *http.Request
+//	@param uid - string
+//	@return error - only returned before the stream started
+func Serve(w http.ResponseWriter, req *http.Request, uid string) error {
+	ctx := req.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrStreamingUnsupported
+	}
+
+	// where to start from
+	lastID, err := lastEventID(req)
+	if err != nil {
+		return err
+	}
+	if lastID < 0 {
+		if lastID, err = latest(ctx); err != nil {
+			return err
+		}
+	}
+
+	// register before reading so no event is missed
+	wake, unsubscribe := hub.subscribe(uid)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryAfter); err != nil {
+		return nil
+	}
+	flusher.Flush()
+
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+
+	for {
+		// send the waiting events
+		for {
+			events, err := since(ctx, uid, lastID)
+			if err != nil {
+				// the client reconnects and resumes
+				rlog.Error("reading live events failed", "uid", uid, "err", err)
+				return nil
+			}
+			for _, event := range events {
+				if _, err := fmt.Fprintf(w, "id: %d\nevent: %v\ndata: %v\n\n", event.ID, event.Name, event.Data); err != nil {
+					return nil
+				}
+				lastID = event.ID
+			}
+			if len(events) > 0 {
+				flusher.Flush()
+				lastWrite = time.Now()
+			}
+			if len(events) < batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-poll.C:
+			// a comment keeps the connection open
+			if time.Since(lastWrite) >= heartbeatInterval {
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return nil
+				}
+				flusher.Flush()
+				lastWrite = time.Now()
+			}
+		}
+	}
+}
+
+// lastEventID - returns the id of the last event the client received, -1 for new clients.
+//
+//	@param req - *http.Request
+//	@return int64
+//	@return error
+func lastEventID(req *http.Request) (int64, error) {
+	value := strings.TrimSpace(req.Header.Get("Last-Event-ID"))
+	if len(value) < 1 {
+		// EventSource polyfills cannot always set headers
+		value = strings.TrimSpace(req.URL.Query().Get("lastEventId"))
+	}
+	if len(value) < 1 {
+		return -1, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, ErrInvalidLastEventID
+	}
+
+	return id, nil
+}
@@ -0,0 +1,25 @@
+package live
+
+import "time"
+
+const (
+	// Retention - how long events are kept for clients resuming with Last-Event-ID
+	Retention = 24 * time.Hour
+	// pollInterval - how often streams check for events published on other instances
+	pollInterval = 5 * time.Second
+	// heartbeatInterval - the longest a stream stays silent, proxies close idle connections
+	heartbeatInterval = 15 * time.Second
+	// retryAfter - how long clients wait before reconnecting, in milliseconds
+	retryAfter = 3000
+	// batchSize - the most events read at once
+	batchSize = 100
+)
+
+// Event - a change sent to the streams of a user
+type Event struct {
+	ID        int64     `json:"id" db:"id"` // increasing, sent as the SSE id
+	UserID    string    `json:"uid" db:"uid"`
+	Name      string    `json:"event" db:"event"` // e.g. "task.updated"
+	Data      string    `json:"data" db:"data"`   // the JSON of the domain event
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,45 @@
+-- the last change of every task and category, deleted rows are kept as tombstones
+CREATE TABLE sync_log (
+  uid             UUID NOT NULL,
+  kind            VARCHAR(16) NOT NULL,
+  entity_id       UUID NOT NULL,
+  txid            BIGINT NOT NULL,
+  deleted         BOOLEAN NOT NULL DEFAULT FALSE,
+  changed_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (uid, kind, entity_id)
+);
+
+CREATE INDEX sync_log_uid_txid_idx ON sync_log (uid, txid, kind, entity_id);
+
+CREATE FUNCTION log_sync_change() RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'DELETE' THEN
+    INSERT INTO sync_log (uid, kind, entity_id, txid, deleted, changed_at)
+    VALUES (OLD.uid, TG_ARGV[0], OLD.id, txid_current(), TRUE, NOW())
+    ON CONFLICT (uid, kind, entity_id) DO UPDATE
+    SET txid = EXCLUDED.txid, deleted = TRUE, changed_at = EXCLUDED.changed_at;
+    RETURN OLD;
+  END IF;
+
+  INSERT INTO sync_log (uid, kind, entity_id, txid, deleted, changed_at)
+  VALUES (NEW.uid, TG_ARGV[0], NEW.id, txid_current(), FALSE, NOW())
+  ON CONFLICT (uid, kind, entity_id) DO UPDATE
+  SET txid = EXCLUDED.txid, deleted = FALSE, changed_at = EXCLUDED.changed_at;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_sync_log
+AFTER INSERT OR UPDATE OR DELETE ON tasks
+FOR EACH ROW EXECUTE PROCEDURE log_sync_change('task');
+
+CREATE TRIGGER categories_sync_log
+AFTER INSERT OR UPDATE OR DELETE ON categories
+FOR EACH ROW EXECUTE PROCEDURE log_sync_change('category');
+
+-- existing rows are changes for clients syncing for the first time
+INSERT INTO sync_log (uid, kind, entity_id, txid)
+SELECT uid, 'task', id, txid_current() FROM tasks;
+
+INSERT INTO sync_log (uid, kind, entity_id, txid)
+SELECT uid, 'category', id, txid_current() FROM categories;
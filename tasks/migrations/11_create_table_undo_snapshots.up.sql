@@ -0,0 +1,11 @@
+CREATE TABLE undo_snapshots (
+  token           VARCHAR(64) NOT NULL PRIMARY KEY,
+  uid             UUID NOT NULL,
+  operation       VARCHAR(64) NOT NULL,
+  entries         TEXT NOT NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  expires_at      TIMESTAMP NOT NULL,
+  undone_at       TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX undo_snapshots_expires_at_idx ON undo_snapshots (expires_at);
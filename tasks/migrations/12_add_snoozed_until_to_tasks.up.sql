@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN snoozed_until TIMESTAMP;
+CREATE INDEX tasks_snoozed_until_idx ON tasks (snoozed_until) WHERE snoozed_until IS NOT NULL;
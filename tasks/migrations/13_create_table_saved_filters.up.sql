@@ -0,0 +1,9 @@
+CREATE TABLE saved_filters (
+  id              UUID NOT NULL PRIMARY KEY,
+  uid             UUID NOT NULL,
+  name            VARCHAR(255) NOT NULL,
+  criteria        TEXT NOT NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  UNIQUE (uid, name)
+);
@@ -0,0 +1,13 @@
+-- colors were not validated, reset the ones that are neither a palette color nor "#rrggbb"
+UPDATE tasks SET color = LOWER(TRIM(color)) WHERE color IS NOT NULL AND color <> LOWER(TRIM(color));
+UPDATE tasks SET color = 'default'
+WHERE color IS NULL
+   OR NOT (color IN ('default', 'red', 'orange', 'yellow', 'green', 'blue', 'purple', 'pink', 'brown', 'grey') OR color ~ '^#[0-9a-f]{6}$');
+ALTER TABLE tasks ALTER COLUMN color TYPE VARCHAR(32);
+ALTER TABLE tasks ALTER COLUMN color SET DEFAULT 'default';
+ALTER TABLE tasks ALTER COLUMN color SET NOT NULL;
+
+UPDATE categories SET color = LOWER(TRIM(color)) WHERE color <> LOWER(TRIM(color));
+UPDATE categories SET color = '#00b3ff'
+WHERE NOT (color IN ('default', 'red', 'orange', 'yellow', 'green', 'blue', 'purple', 'pink', 'brown', 'grey') OR color ~ '^#[0-9a-f]{6}$');
+ALTER TABLE categories ALTER COLUMN color TYPE VARCHAR(32);
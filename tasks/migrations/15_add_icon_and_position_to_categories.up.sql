@@ -0,0 +1,12 @@
+ALTER TABLE categories ADD COLUMN icon VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- existing categories keep the order they were created in
+UPDATE categories SET position = ordered.position
+FROM (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY uid ORDER BY created_at, id) - 1 AS position FROM categories
+) ordered
+WHERE categories.id = ordered.id;
+
+CREATE INDEX categories_uid_position_idx ON categories (uid, position);
+CREATE INDEX tasks_uid_category_idx ON tasks (uid, category);
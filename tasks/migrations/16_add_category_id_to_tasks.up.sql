@@ -0,0 +1,46 @@
+-- every category name used by tasks becomes a category of its user, "general" was the default and means no category
+INSERT INTO categories (id, uid, name, description, position, created_at, updated_at)
+SELECT
+  gen_random_uuid(), used.uid, used.category, '',
+  COALESCE((SELECT MAX(position) + 1 FROM categories WHERE categories.uid = used.uid), 0)
+    + ROW_NUMBER() OVER (PARTITION BY used.uid ORDER BY used.category) - 1,
+  NOW(), NOW()
+FROM (SELECT DISTINCT uid, category FROM tasks WHERE category NOT IN ('', 'general')) used
+WHERE NOT EXISTS (SELECT 1 FROM categories WHERE categories.uid = used.uid AND categories.name = used.category);
+
+ALTER TABLE tasks ADD COLUMN category_id UUID REFERENCES categories (id) ON DELETE SET NULL;
+
+-- link the tasks to the oldest category of their user with the name
+UPDATE tasks SET category_id = named.id
+FROM (
+  SELECT DISTINCT ON (uid, name) id, uid, name FROM categories ORDER BY uid, name, created_at, id
+) named
+WHERE named.uid = tasks.uid AND named.name = tasks.category;
+
+CREATE INDEX tasks_category_id_idx ON tasks (category_id);
+
+-- the category name of a task is kept for reading, it always follows the category id
+UPDATE tasks SET category = '' WHERE category_id IS NULL AND category <> '';
+ALTER TABLE tasks ALTER COLUMN category SET DEFAULT '';
+
+CREATE FUNCTION set_task_category_name() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.category := COALESCE((SELECT name FROM categories WHERE id = NEW.category_id), '');
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_category_name
+BEFORE INSERT OR UPDATE OF category, category_id ON tasks
+FOR EACH ROW EXECUTE PROCEDURE set_task_category_name();
+
+CREATE FUNCTION rename_task_categories() RETURNS TRIGGER AS $$
+BEGIN
+  UPDATE tasks SET category = NEW.name WHERE category_id = NEW.id;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER categories_rename_tasks
+AFTER UPDATE OF name ON categories
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE PROCEDURE rename_task_categories();
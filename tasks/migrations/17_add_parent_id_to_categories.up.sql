@@ -0,0 +1,4 @@
+-- categories can be nested, the children of a deleted category are moved to its parent
+ALTER TABLE categories ADD COLUMN parent_id UUID REFERENCES categories (id) ON DELETE SET NULL;
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
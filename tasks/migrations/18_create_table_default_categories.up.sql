@@ -0,0 +1,22 @@
+-- the categories new users start with, managed by admins
+CREATE TABLE default_categories (
+  name            VARCHAR(255) NOT NULL PRIMARY KEY,
+  description     TEXT NOT NULL DEFAULT '',
+  color           VARCHAR(255) NOT NULL DEFAULT '#00b3ff',
+  icon            VARCHAR(64) NOT NULL DEFAULT '',
+  position        INTEGER NOT NULL,
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- 'general' is the built-in calendar of uncategorized tasks, it is never a category
+INSERT INTO default_categories (name, position) VALUES
+  ('work', 0),
+  ('personal', 1),
+  ('shopping', 2),
+  ('others', 3);
+
+-- the users whose default categories were created, so they are created once
+CREATE TABLE category_seeds (
+  uid             UUID NOT NULL PRIMARY KEY,
+  seeded_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,23 @@
+-- categories named like an older category of their user are merged into it
+CREATE TEMPORARY TABLE duplicate_categories AS
+SELECT id, keep FROM (
+  SELECT id, FIRST_VALUE(id) OVER (PARTITION BY uid, LOWER(name) ORDER BY created_at, id) AS keep FROM categories
+) ranked
+WHERE id <> keep;
+
+UPDATE tasks SET category_id = duplicate_categories.keep, updated_at = NOW()
+FROM duplicate_categories
+WHERE tasks.category_id = duplicate_categories.id;
+
+UPDATE categories SET parent_id = NULLIF(duplicate_categories.keep, categories.id), updated_at = NOW()
+FROM duplicate_categories
+WHERE categories.parent_id = duplicate_categories.id;
+
+DELETE FROM categories USING duplicate_categories WHERE categories.id = duplicate_categories.id;
+
+DROP TABLE duplicate_categories;
+
+-- names are stored in lower case like new categories
+UPDATE categories SET name = LOWER(name), updated_at = NOW() WHERE name <> LOWER(name);
+
+CREATE UNIQUE INDEX categories_uid_name_key ON categories (uid, LOWER(name));
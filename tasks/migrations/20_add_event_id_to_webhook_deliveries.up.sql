@@ -0,0 +1,8 @@
+-- deliveries are keyed on the event they were dispatched for, so a redelivered event is not sent twice
+ALTER TABLE webhook_deliveries ADD COLUMN event_id TEXT;
+
+UPDATE webhook_deliveries SET event_id = CAST(id AS TEXT);
+
+ALTER TABLE webhook_deliveries ALTER COLUMN event_id SET NOT NULL;
+
+CREATE UNIQUE INDEX webhook_deliveries_webhook_id_event_id_key ON webhook_deliveries (webhook_id, event_id);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP DEFAULT NULL;
+
+CREATE INDEX tasks_uid_due_at_idx ON tasks (uid, due_at) WHERE due_at IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE feed_tokens (
+  uid             UUID NOT NULL PRIMARY KEY,
+  token_hash      TEXT NOT NULL UNIQUE,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN priority VARCHAR(255) NOT NULL DEFAULT 'none';
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
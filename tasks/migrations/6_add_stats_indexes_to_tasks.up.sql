@@ -0,0 +1,2 @@
+CREATE INDEX tasks_uid_created_at_idx ON tasks (uid, created_at);
+CREATE INDEX tasks_uid_completed_at_idx ON tasks (uid, completed_at) WHERE completed;
@@ -0,0 +1,10 @@
+CREATE TABLE streaks (
+  uid             UUID NOT NULL PRIMARY KEY,
+  daily_goal      INTEGER NOT NULL DEFAULT 1,
+  current_streak  INTEGER NOT NULL DEFAULT 0,
+  longest_streak  INTEGER NOT NULL DEFAULT 0,
+  started_on      DATE DEFAULT NULL,
+  last_met_on     DATE DEFAULT NULL,
+  milestone       INTEGER NOT NULL DEFAULT 0,
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
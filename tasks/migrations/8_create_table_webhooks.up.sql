@@ -0,0 +1,31 @@
+CREATE TABLE webhooks (
+  id              UUID NOT NULL PRIMARY KEY,
+  uid             UUID NOT NULL,
+  url             TEXT NOT NULL,
+  description     TEXT NOT NULL DEFAULT '',
+  secret          TEXT NOT NULL,
+  events          TEXT NOT NULL DEFAULT '',
+  enabled         BOOLEAN NOT NULL DEFAULT TRUE,
+  failures        INTEGER NOT NULL DEFAULT 0,
+  disabled_at     TIMESTAMP DEFAULT NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhooks_uid_idx ON webhooks (uid);
+
+CREATE TABLE webhook_deliveries (
+  id              UUID NOT NULL PRIMARY KEY,
+  webhook_id      UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  event           VARCHAR(64) NOT NULL,
+  payload         TEXT NOT NULL,
+  status          VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts        INTEGER NOT NULL DEFAULT 0,
+  response_status INTEGER DEFAULT NULL,
+  error           TEXT NOT NULL DEFAULT '',
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+  delivered_at    TIMESTAMP DEFAULT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_created_at_idx ON webhook_deliveries (webhook_id, created_at DESC);
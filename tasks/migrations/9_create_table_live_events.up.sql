@@ -0,0 +1,10 @@
+CREATE TABLE live_events (
+  id              BIGSERIAL PRIMARY KEY,
+  uid             UUID NOT NULL,
+  event           VARCHAR(64) NOT NULL,
+  data            TEXT NOT NULL,
+  created_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX live_events_uid_id_idx ON live_events (uid, id);
+CREATE INDEX live_events_created_at_idx ON live_events (created_at);
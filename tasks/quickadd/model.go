@@ -0,0 +1,19 @@
+package quickadd
+
+import (
+	"encore.app/tasks/quickadd/phrase"
+	"encore.app/tasks/ts"
+)
+
+type QuickAddPayload struct {
+	Text     string `json:"text" validate:"required"`               // required, e.g. "Pay rent tomorrow 9am #personal !high every month"
+	Timezone string `json:"timezone" validate:"omitempty,timezone"` // optional, default: the user's timezone
+	Confirm  bool   `json:"confirm"`                                // create the task, default: false -> preview only
+}
+
+type QuickAddResponse struct {
+	Task     ts.CreateTaskPayload `json:"task"`     // the parsed task, can be sent to the create endpoint as is
+	Matches  []phrase.Match       `json:"matches"`  // the parts of the text that were understood
+	Timezone string               `json:"timezone"` // the timezone dates were resolved in
+	Created  bool                 `json:"created"`  // true when the task was created
+}
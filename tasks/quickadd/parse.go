@@ -0,0 +1,43 @@
+package quickadd
+
+import (
+	"time"
+
+	"encore.app/tasks/quickadd/phrase"
+	"encore.app/tasks/ts"
+)
+
+// priorities - the task priorities of the parsed priority markers
+var priorities = map[string]string{
+	phrase.PriorityHigh:   ts.PriorityHigh,
+	phrase.PriorityMedium: ts.PriorityMedium,
+	phrase.PriorityLow:    ts.PriorityLow,
+}
+
+// Parse - parses quick-add text such as "Pay rent tomorrow 9am #personal !high every month" into a task.
+// Dates are resolved against now, whose location is the timezone of the user.
+// Words that are not understood are kept as the title.
+//
+//	@param text - string
+//	@param now - time.Time
+//	@return *ts.CreateTaskPayload
+//	@return []phrase.Match
+//	@return error
+func Parse(text string, now time.Time) (*ts.CreateTaskPayload, []phrase.Match, error) {
+	result, err := phrase.Parse(text, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload := &ts.CreateTaskPayload{
+		Title:      result.Title,
+		Category:   result.Category,
+		Priority:   priorities[result.Priority],
+		Recurrence: result.Recurrence,
+	}
+	if result.DueAt != nil {
+		payload.DueAt = result.DueAt.Format(time.RFC3339)
+	}
+
+	return payload, result.Matches, nil
+}
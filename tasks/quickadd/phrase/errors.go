@@ -0,0 +1,8 @@
+package phrase
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrEmptyTitle - the text has nothing left for the title
+	ErrEmptyTitle = &errs.Error{Code: errs.InvalidArgument, Message: "quick add text has no title"}
+)
@@ -0,0 +1,55 @@
+package phrase
+
+import "time"
+
+// kinds of the matched parts of a text
+const (
+	KindDue        = "due"
+	KindCategory   = "category"
+	KindPriority   = "priority"
+	KindRecurrence = "recurrence"
+)
+
+// priorities of the priority markers, the same values as the task priorities
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// Result - the fields of a task parsed from a text
+type Result struct {
+	Title      string
+	Category   string     // empty when the text has no #category
+	Priority   string     // empty when the text has no priority marker
+	Recurrence string     // an RRULE, empty when the task does not repeat
+	DueAt      *time.Time // nil when the text has no date or time
+	Matches    []Match    // the parts of the text that were understood
+}
+
+// Match - a part of the text that was parsed into a field
+type Match struct {
+	Kind string `json:"kind"` // due, category, priority, recurrence
+	Text string `json:"text"` // the words as written
+}
+
+// clock - a time of day
+type clock struct {
+	Hour   int
+	Minute int
+}
+
+// parser - the state of a text being parsed
+type parser struct {
+	now        time.Time // the reference time, in the timezone of the user
+	tokens     []string  // the words as written
+	words      []string  // the words lowercased without trailing punctuation
+	date       *time.Time
+	clock      *clock
+	instant    *time.Time // an exact due time, e.g. "in 2 hours"
+	category   string
+	priority   string
+	recurrence string
+	byDay      []time.Weekday
+	matches    []Match
+}
@@ -0,0 +1,606 @@
+package phrase
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultClock - the time of tasks due on a day without a time
+var defaultClock = clock{Hour: 9}
+
+// weekdays - the names of the days of the week
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+// weekdayAbbreviations - short day names, only understood after "on", "next" or "every"
+var weekdayAbbreviations = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// byDay - the RRULE BYDAY values of the days of the week
+var byDay = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// months - the names of the months
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January, "feb": time.February, "february": time.February,
+	"mar": time.March, "march": time.March, "apr": time.April, "april": time.April, "may": time.May,
+	"jun": time.June, "june": time.June, "jul": time.July, "july": time.July, "aug": time.August,
+	"august": time.August, "sep": time.September, "sept": time.September, "september": time.September,
+	"oct": time.October, "october": time.October, "nov": time.November, "november": time.November,
+	"dec": time.December, "december": time.December,
+}
+
+// numbers - the numbers which may be written as words
+var numbers = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+}
+
+// priorities - the priority markers
+var priorities = map[string]string{
+	"!high": PriorityHigh, "!h": PriorityHigh, "!1": PriorityHigh, "!!!": PriorityHigh,
+	"!medium": PriorityMedium, "!med": PriorityMedium, "!m": PriorityMedium, "!2": PriorityMedium, "!!": PriorityMedium,
+	"!low": PriorityLow, "!l": PriorityLow, "!3": PriorityLow,
+}
+
+// frequencies - the RRULE FREQ values of the units of time
+var frequencies = map[string]string{
+	"day": "DAILY", "days": "DAILY", "week": "WEEKLY", "weeks": "WEEKLY",
+	"month": "MONTHLY", "months": "MONTHLY", "year": "YEARLY", "years": "YEARLY",
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a|p)?$`)
+	isoDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dayPattern     = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// Parse - parses quick-add text such as "Pay rent tomorrow 9am #personal !high every month".
+// Dates are resolved against now, whose location is the timezone of the user.
+// Words that are not understood are kept as the title.
+//
+//	@param text - string
+//	@param now - time.Time
+//	@return *Result
+//	@return error
+func Parse(text string, now time.Time) (*Result, error) {
+	p := &parser{now: now, tokens: strings.Fields(text), matches: []Match{}}
+	for _, token := range p.tokens {
+		p.words = append(p.words, strings.TrimRight(strings.ToLower(token), ",;"))
+	}
+
+	// the matchers, in the order they are tried at each word
+	matchers := []func(int) int{p.matchCategory, p.matchPriority, p.matchRecurrence, p.matchDate, p.matchClock}
+
+	// keep the words no matcher consumed as the title
+	var title []string
+	for i := 0; i < len(p.words); {
+		consumed := 0
+		for _, match := range matchers {
+			if consumed = match(i); consumed > 0 {
+				break
+			}
+		}
+		if consumed == 0 {
+			title = append(title, p.tokens[i])
+			consumed = 1
+		}
+		i += consumed
+	}
+
+	if len(title) < 1 {
+		return nil, ErrEmptyTitle
+	}
+
+	return &Result{
+		Title:      strings.Join(title, " "),
+		Category:   p.category,
+		Priority:   p.priority,
+		Recurrence: p.recurrence,
+		DueAt:      p.due(),
+		Matches:    p.matches,
+	}, nil
+}
+
+// due - returns the due time of the parsed text, nil when the text has none.
+// @return *time.Time
+func (p *parser) due() *time.Time {
+	if p.instant != nil {
+		return p.instant
+	}
+
+	// repeating weekdays start at their next occurrence
+	if p.date == nil && len(p.byDay) > 0 {
+		date := p.nextWeekday(p.byDay, true)
+		p.date = &date
+	}
+
+	if p.date == nil && p.clock == nil {
+		return nil
+	}
+
+	// a time without a day is the next time that time comes
+	if p.date == nil {
+		today := p.today()
+		due := p.at(today, *p.clock)
+		if !due.After(p.now) {
+			due = p.at(today.AddDate(0, 0, 1), *p.clock)
+		}
+		return &due
+	}
+
+	c := defaultClock
+	if p.clock != nil {
+		c = *p.clock
+	}
+	due := p.at(*p.date, c)
+
+	return &due
+}
+
+// matchCategory - matches "#category".
+//
+//	@param i - int
+//	@return int - the number of words consumed
+func (p *parser) matchCategory(i int) int {
+	name, ok := strings.CutPrefix(p.words[i], "#")
+	if !ok || len(name) < 1 || len(p.category) > 0 {
+		return 0
+	}
+
+	p.category = name
+	return p.match(KindCategory, i, 1)
+}
+
+// matchPriority - matches "!high", "!2", "!!!"...
+//
+//	@param i - int
+//	@return int - the number of words consumed
+func (p *parser) matchPriority(i int) int {
+	priority, ok := priorities[p.words[i]]
+	if !ok || len(p.priority) > 0 {
+		return 0
+	}
+
+	p.priority = priority
+	return p.match(KindPriority, i, 1)
+}
+
+// matchRecurrence - matches "daily", "every month", "every 2 weeks", "every other day", "every weekday",
+// "every monday and thursday"...
+//
+//	@param i - int
+//	@return int - the number of words consumed
+func (p *parser) matchRecurrence(i int) int {
+	if len(p.recurrence) > 0 {
+		return 0
+	}
+
+	switch p.words[i] {
+	case "daily":
+		p.recurrence = "FREQ=DAILY"
+		return p.match(KindRecurrence, i, 1)
+	case "weekly":
+		p.recurrence = "FREQ=WEEKLY"
+		return p.match(KindRecurrence, i, 1)
+	case "monthly":
+		p.recurrence = "FREQ=MONTHLY"
+		return p.match(KindRecurrence, i, 1)
+	case "yearly", "annually":
+		p.recurrence = "FREQ=YEARLY"
+		return p.match(KindRecurrence, i, 1)
+	case "every":
+	default:
+		return 0
+	}
+
+	// every weekday, every weekend
+	switch p.word(i + 1) {
+	case "weekday", "weekdays":
+		p.repeatOn([]time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday})
+		return p.match(KindRecurrence, i, 2)
+	case "weekend", "weekends":
+		p.repeatOn([]time.Weekday{time.Saturday, time.Sunday})
+		return p.match(KindRecurrence, i, 2)
+	}
+
+	// every monday, wednesday and friday
+	var days []time.Weekday
+	n := 1
+	for {
+		day, ok := p.weekday(p.word(i+n), true)
+		if !ok {
+			break
+		}
+		days = append(days, day)
+		n++
+		if p.word(i+n) == "and" {
+			if _, ok := p.weekday(p.word(i+n+1), true); ok {
+				n++
+			}
+		}
+	}
+	if len(days) > 0 {
+		p.repeatOn(days)
+		return p.match(KindRecurrence, i, n)
+	}
+
+	// every day, every 2 weeks, every other month
+	interval, n := 1, 1
+	if p.word(i+1) == "other" {
+		interval, n = 2, 2
+	} else if number, ok := p.number(p.word(i + 1)); ok {
+		interval, n = number, 2
+	}
+	freq, ok := frequencies[p.word(i+n)]
+	if !ok || interval < 1 {
+		return 0
+	}
+
+	p.recurrence = fmt.Sprintf("FREQ=%v", freq)
+	if interval > 1 {
+		p.recurrence = fmt.Sprintf("%v;INTERVAL=%v", p.recurrence, interval)
+	}
+
+	return p.match(KindRecurrence, i, n+1)
+}
+
+// matchDate - matches "today", "tomorrow", "friday", "next week", "in 3 days", "2024-06-01", "june 1st"...
+// optionally preceded by "on", "by" or "due".
+//
+//	@param i - int
+//	@return int - the number of words consumed
+func (p *parser) matchDate(i int) int {
+	if p.date != nil || p.instant != nil {
+		return 0
+	}
+
+	// the prefix is consumed with the date
+	prefix := 0
+	switch p.words[i] {
+	case "on", "by", "due":
+		prefix = 1
+	}
+
+	n := p.parseDate(i+prefix, prefix > 0)
+	if n == 0 {
+		return 0
+	}
+
+	return p.match(KindDue, i, prefix+n)
+}
+
+// parseDate - matches a date at i without a prefix.
+//
+//	@param i - int
+//	@param prefixed - the date follows "on", "by" or "due", abbreviated day names are allowed
+//	@return int - the number of words consumed
+func (p *parser) parseDate(i int, prefixed bool) int {
+	today := p.today()
+	word := p.word(i)
+
+	switch word {
+	case "today", "tod":
+		p.date = &today
+		return 1
+	case "tonight":
+		p.date = &today
+		if p.clock == nil {
+			p.clock = &clock{Hour: 20}
+		}
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		date := today.AddDate(0, 0, 1)
+		p.date = &date
+		return 1
+	case "next":
+		return p.next(i)
+	case "in":
+		return p.in(i)
+	}
+
+	// friday
+	if day, ok := p.weekday(word, prefixed); ok {
+		date := p.nextWeekday([]time.Weekday{day}, false)
+		p.date = &date
+		return 1
+	}
+
+	// 2024-06-01
+	if isoDatePattern.MatchString(word) {
+		date, err := time.ParseInLocation("2006-01-02", word, p.now.Location())
+		if err != nil {
+			return 0
+		}
+		p.date = &date
+		return 1
+	}
+
+	// june 1st 2025, 1st june 2025
+	month, monthOK := months[word]
+	day, dayOK := p.dayOfMonth(p.word(i + 1))
+	if !monthOK || !dayOK {
+		day, dayOK = p.dayOfMonth(word)
+		month, monthOK = months[p.word(i+1)]
+	}
+	if !monthOK || !dayOK {
+		return 0
+	}
+
+	// without a year, the next time the date comes
+	date := time.Date(today.Year(), month, day, 0, 0, 0, 0, p.now.Location())
+	n := 2
+	if year, err := strconv.Atoi(p.word(i + 2)); err == nil && year >= today.Year() && year < today.Year()+100 {
+		date = time.Date(year, month, day, 0, 0, 0, 0, p.now.Location())
+		n = 3
+	} else if date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	// reject dates such as february 30th
+	if date.Day() != day {
+		return 0
+	}
+	p.date = &date
+
+	return n
+}
+
+// next - matches "next week", "next month", "next year" and "next friday".
+//
+//	@param i - the index of "next"
+//	@return int - the number of words consumed
+func (p *parser) next(i int) int {
+	today := p.today()
+	// the monday of next week
+	nextWeek := today.AddDate(0, 0, 7-(int(today.Weekday())+6)%7)
+
+	var date time.Time
+	switch word := p.word(i + 1); word {
+	case "week":
+		date = nextWeek
+	case "month":
+		date = time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, p.now.Location())
+	case "year":
+		date = time.Date(today.Year()+1, time.January, 1, 0, 0, 0, 0, p.now.Location())
+	default:
+		// the day in next week
+		day, ok := p.weekday(word, true)
+		if !ok {
+			return 0
+		}
+		date = nextWeek.AddDate(0, 0, (int(day)+6)%7)
+	}
+	p.date = &date
+
+	return 2
+}
+
+// in - matches "in 3 days", "in a week", "in 2 hours", "in 30 minutes"...
+//
+//	@param i - the index of "in"
+//	@return int - the number of words consumed
+func (p *parser) in(i int) int {
+	number, ok := p.number(p.word(i + 1))
+	if !ok {
+		return 0
+	}
+
+	today := p.today()
+	var date time.Time
+	switch p.word(i + 2) {
+	case "day", "days":
+		date = today.AddDate(0, 0, number)
+	case "week", "weeks":
+		date = today.AddDate(0, 0, 7*number)
+	case "month", "months":
+		date = today.AddDate(0, number, 0)
+	case "year", "years":
+		date = today.AddDate(number, 0, 0)
+	case "hour", "hours", "hr", "hrs", "h":
+		instant := p.now.Add(time.Duration(number) * time.Hour).Truncate(time.Minute)
+		p.instant = &instant
+		return 3
+	case "minute", "minutes", "min", "mins", "m":
+		instant := p.now.Add(time.Duration(number) * time.Minute).Truncate(time.Minute)
+		p.instant = &instant
+		return 3
+	default:
+		return 0
+	}
+	p.date = &date
+
+	return 3
+}
+
+// matchClock - matches "9am", "9:30 pm", "21:00", "noon", "midnight", optionally preceded by "at" or "@".
+// Without "at", a time needs minutes or am/pm so numbers in the title are kept.
+// Times from 1 to 7 without am/pm are in the afternoon.
+//
+//	@param i - int
+//	@return int - the number of words consumed
+func (p *parser) matchClock(i int) int {
+	if p.clock != nil || p.instant != nil {
+		return 0
+	}
+
+	prefix := 0
+	switch p.words[i] {
+	case "at", "@":
+		prefix = 1
+	}
+
+	word := p.word(i + prefix)
+	switch word {
+	case "noon", "midday":
+		p.clock = &clock{Hour: 12}
+		return p.match(KindDue, i, prefix+1)
+	case "midnight":
+		p.clock = &clock{}
+		return p.match(KindDue, i, prefix+1)
+	}
+
+	groups := clockPattern.FindStringSubmatch(word)
+	if groups == nil {
+		return 0
+	}
+
+	// "9 am"
+	n := 1
+	meridiem := groups[3]
+	if len(meridiem) < 1 {
+		switch next := p.word(i + prefix + 1); next {
+		case "am", "pm", "a.m.", "p.m.":
+			meridiem, n = next[:1], 2
+		}
+	}
+	// bare numbers are only times after "at"
+	if prefix == 0 && len(meridiem) < 1 && len(groups[2]) < 1 {
+		return 0
+	}
+
+	hour, _ := strconv.Atoi(groups[1])
+	minute, _ := strconv.Atoi(groups[2])
+	if minute > 59 || hour > 23 || (len(meridiem) > 0 && (hour < 1 || hour > 12)) {
+		return 0
+	}
+	switch {
+	// "at 5" is in the afternoon
+	case len(meridiem) < 1 && !strings.HasPrefix(groups[1], "0") && hour >= 1 && hour <= 7:
+		hour += 12
+	case strings.HasPrefix(meridiem, "p") && hour < 12:
+		hour += 12
+	case strings.HasPrefix(meridiem, "a") && hour == 12:
+		hour = 0
+	}
+	p.clock = &clock{Hour: hour, Minute: minute}
+
+	return p.match(KindDue, i, prefix+n)
+}
+
+// match - records the words of a match.
+//
+//	@param kind - string
+//	@param i - the index of the first word
+//	@param n - the number of words
+//	@return int - n
+func (p *parser) match(kind string, i, n int) int {
+	p.matches = append(p.matches, Match{Kind: kind, Text: strings.Join(p.tokens[i:i+n], " ")})
+	return n
+}
+
+// repeatOn - repeats the task weekly on the days.
+//
+//	@param days - []time.Weekday
+func (p *parser) repeatOn(days []time.Weekday) {
+	var values []string
+	for _, day := range days {
+		values = append(values, byDay[day])
+	}
+
+	p.byDay = days
+	p.recurrence = fmt.Sprintf("FREQ=WEEKLY;BYDAY=%v", strings.Join(values, ","))
+}
+
+// word - returns the word at i, empty when there is none.
+//
+//	@param i - int
+//	@return string
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.words) {
+		return ""
+	}
+	return p.words[i]
+}
+
+// number - returns the number a word is, written as digits or as a word.
+//
+//	@param word - string
+//	@return int
+//	@return bool
+func (p *parser) number(word string) (int, bool) {
+	if n, ok := numbers[word]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(word)
+	return n, err == nil && n > 0
+}
+
+// weekday - returns the day of the week a word names.
+//
+//	@param word - string
+//	@param abbreviated - allow short names such as "mon"
+//	@return time.Weekday
+//	@return bool
+func (p *parser) weekday(word string, abbreviated bool) (time.Weekday, bool) {
+	if day, ok := weekdays[word]; ok {
+		return day, true
+	}
+	if day, ok := weekdayAbbreviations[word]; ok && abbreviated {
+		return day, true
+	}
+	return 0, false
+}
+
+// dayOfMonth - returns the day of the month of "1", "1st", "22nd"...
+//
+//	@param word - string
+//	@return int
+//	@return bool
+func (p *parser) dayOfMonth(word string) (int, bool) {
+	groups := dayPattern.FindStringSubmatch(word)
+	if groups == nil {
+		return 0, false
+	}
+
+	day, _ := strconv.Atoi(groups[1])
+	return day, day >= 1 && day <= 31
+}
+
+// today - returns the start of the day of now.
+// @return time.Time
+func (p *parser) today() time.Time {
+	return time.Date(p.now.Year(), p.now.Month(), p.now.Day(), 0, 0, 0, 0, p.now.Location())
+}
+
+// nextWeekday - returns the next day which is one of days.
+//
+//	@param days - []time.Weekday
+//	@param includeToday - today counts when its time has not passed
+//	@return time.Time
+func (p *parser) nextWeekday(days []time.Weekday, includeToday bool) time.Time {
+	today := p.today()
+
+	c := defaultClock
+	if p.clock != nil {
+		c = *p.clock
+	}
+
+	for offset := 0; offset <= 7; offset++ {
+		if offset == 0 && (!includeToday || !p.at(today, c).After(p.now)) {
+			continue
+		}
+		date := today.AddDate(0, 0, offset)
+		for _, day := range days {
+			if date.Weekday() == day {
+				return date
+			}
+		}
+	}
+
+	return today
+}
+
+// at - returns the time of day on a date.
+//
+//	@param date - time.Time
+//	@param c - clock
+//	@return time.Time
+func (p *parser) at(date time.Time, c clock) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), c.Hour, c.Minute, 0, 0, p.now.Location())
+}
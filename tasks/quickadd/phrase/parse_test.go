@@ -0,0 +1,108 @@
+package phrase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday, June 5th 2024 at 10:00
+	now := time.Date(2024, time.June, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		text       string
+		title      string
+		dueAt      string
+		category   string
+		priority   string
+		recurrence string
+	}{
+		// relative dates
+		{name: "today", text: "Water plants today", title: "Water plants", dueAt: "2024-06-05T09:00:00Z"},
+		{name: "tomorrow", text: "Buy milk tomorrow", title: "Buy milk", dueAt: "2024-06-06T09:00:00Z"},
+		{name: "tomorrow with a time", text: "Dentist tomorrow at 3pm", title: "Dentist", dueAt: "2024-06-06T15:00:00Z"},
+		{name: "in days", text: "Call mom in 3 days", title: "Call mom", dueAt: "2024-06-08T09:00:00Z"},
+		{name: "in a week", text: "Renew passport in a week", title: "Renew passport", dueAt: "2024-06-12T09:00:00Z"},
+		{name: "in hours", text: "Check oven in 2 hours", title: "Check oven", dueAt: "2024-06-05T12:00:00Z"},
+		{name: "next week", text: "Plan sprint next week", title: "Plan sprint", dueAt: "2024-06-10T09:00:00Z"},
+		{name: "next month", text: "Pay insurance next month", title: "Pay insurance", dueAt: "2024-07-01T09:00:00Z"},
+		{name: "time still to come today", text: "Lunch at noon", title: "Lunch", dueAt: "2024-06-05T12:00:00Z"},
+		{name: "time passed today", text: "Alarm 9am", title: "Alarm", dueAt: "2024-06-06T09:00:00Z"},
+
+		// weekday rollover
+		{name: "later this week", text: "Gym friday", title: "Gym", dueAt: "2024-06-07T09:00:00Z"},
+		{name: "weekday of today", text: "Standup wednesday", title: "Standup", dueAt: "2024-06-12T09:00:00Z"},
+		{name: "weekday before today", text: "Groceries on mon", title: "Groceries", dueAt: "2024-06-10T09:00:00Z"},
+		{name: "next weekday", text: "Retro next wednesday", title: "Retro", dueAt: "2024-06-12T09:00:00Z"},
+		{name: "repeating weekday passed today", text: "Review every wednesday", title: "Review", dueAt: "2024-06-12T09:00:00Z", recurrence: "FREQ=WEEKLY;BYDAY=WE"},
+		{name: "repeating weekday later today", text: "Review every wednesday at 11am", title: "Review", dueAt: "2024-06-05T11:00:00Z", recurrence: "FREQ=WEEKLY;BYDAY=WE"},
+		{name: "date passed this year", text: "Birthday january 2nd", title: "Birthday", dueAt: "2025-01-02T09:00:00Z"},
+
+		// tags and priorities at the start and the end
+		{name: "tag at the end", text: "Send report #work", title: "Send report", category: "work"},
+		{name: "tag at the start", text: "#work Send report", title: "Send report", category: "work"},
+		{name: "priority at the end", text: "Fix bug !high", title: "Fix bug", priority: PriorityHigh},
+		{name: "priority at the start", text: "!2 Fix bug", title: "Fix bug", priority: PriorityMedium},
+		{name: "tag and priority at the start", text: "#home !!! Pay rent", title: "Pay rent", category: "home", priority: PriorityHigh},
+		{name: "tag and priority at both ends", text: "!low Read book #personal", title: "Read book", category: "personal", priority: PriorityLow},
+		{name: "second tag is kept", text: "#work Email #urgent", title: "Email #urgent", category: "work"},
+
+		// everything
+		{name: "everything", text: "Pay rent tomorrow 9am #personal !high every month", title: "Pay rent", dueAt: "2024-06-06T09:00:00Z", category: "personal", priority: PriorityHigh, recurrence: "FREQ=MONTHLY"},
+		{name: "nothing", text: "Read 2 chapters", title: "Read 2 chapters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.text, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed", tt.text)
+			}
+
+			dueAt := ""
+			if result.DueAt != nil {
+				dueAt = result.DueAt.Format(time.RFC3339)
+			}
+
+			if result.Title != tt.title {
+				t.Errorf("title = %q, want %q", result.Title, tt.title)
+			}
+			if dueAt != tt.dueAt {
+				t.Errorf("dueAt = %q, want %q", dueAt, tt.dueAt)
+			}
+			if result.Category != tt.category {
+				t.Errorf("category = %q, want %q", result.Category, tt.category)
+			}
+			if result.Priority != tt.priority {
+				t.Errorf("priority = %q, want %q", result.Priority, tt.priority)
+			}
+			if result.Recurrence != tt.recurrence {
+				t.Errorf("recurrence = %q, want %q", result.Recurrence, tt.recurrence)
+			}
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	now := time.Date(2024, time.June, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "empty", text: ""},
+		{name: "blank", text: "   "},
+		{name: "only a tag and a priority", text: "#work !high"},
+		{name: "only a date", text: "tomorrow at 9am"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// errs.Error needs the encore runtime to be printed, it is compared only
+			if _, err := Parse(tt.text, now); err != ErrEmptyTitle {
+				t.Errorf("Parse(%q) did not fail with ErrEmptyTitle", tt.text)
+			}
+		})
+	}
+}
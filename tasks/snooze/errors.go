@@ -0,0 +1,8 @@
+package snooze
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrInPast - the snooze would end before now
+	ErrInPast = &errs.Error{Code: errs.InvalidArgument, Message: "snooze must end in the future"}
+)
@@ -0,0 +1,27 @@
+package snooze
+
+import "encore.app/tasks/ts"
+
+// the presets a task can be snoozed with, resolved in the user's timezone
+const (
+	PresetLaterToday = "later_today" // three hours from now, on the hour
+	PresetTomorrow   = "tomorrow"    // tomorrow at 9:00
+	PresetNextWeek   = "next_week"   // next Monday at 9:00
+)
+
+// MorningHour - the hour of the day tasks snoozed to another day come back
+const MorningHour = 9
+
+// SnoozePayload - snoozes a task until a preset or a time
+type SnoozePayload struct {
+	// later_today, tomorrow, next_week
+	Preset string `json:"preset" validate:"required_without=Until,excluded_with=Until,omitempty,oneof=later_today tomorrow next_week"`
+	// RFC 3339, instead of a preset
+	Until    string `json:"until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Timezone string `json:"timezone" validate:"omitempty,timezone"` // default: the user's timezone
+}
+
+type SnoozeResponse struct {
+	Task     ts.Task `json:"task"`
+	Timezone string  `json:"timezone"` // the timezone the preset was resolved in
+}
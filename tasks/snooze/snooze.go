@@ -0,0 +1,57 @@
+package snooze
+
+import (
+	"fmt"
+	"time"
+)
+
+// Until - returns the time a snooze ends, from the preset or the time of the payload.
+//
+//	@param payload - *SnoozePayload
+//	@param now - the current time in the timezone the preset is resolved in
+//	@return time.Time
+//	@return error
+func Until(payload *SnoozePayload, now time.Time) (time.Time, error) {
+	var until time.Time
+
+	switch payload.Preset {
+	case PresetLaterToday:
+		hour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+		until = hour.Add(3 * time.Hour)
+		if hour.Before(now) {
+			until = until.Add(time.Hour)
+		}
+	case PresetTomorrow:
+		until = morning(now, 1)
+	case PresetNextWeek:
+		// days until the next Monday, a week on Mondays
+		days := (8 - int(now.Weekday())) % 7
+		if days == 0 {
+			days = 7
+		}
+		until = morning(now, days)
+	case "":
+		parsed, err := time.Parse(time.RFC3339, payload.Until)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing snooze time: %w", err)
+		}
+		until = parsed
+	default:
+		return time.Time{}, fmt.Errorf("unknown snooze preset %q", payload.Preset)
+	}
+
+	if !until.After(now) {
+		return time.Time{}, ErrInPast
+	}
+
+	return until, nil
+}
+
+// morning - returns the morning hour a number of days after now, in the location of now.
+//
+//	@param now - time.Time
+//	@param days - int
+//	@return time.Time
+func morning(now time.Time, days int) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+days, MorningHour, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,179 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+)
+
+// get the service name
+var tasksDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// limits of the range
+const (
+	defaultDays = 30
+	maxDays     = 366 * 2
+)
+
+// timestampLayout - the layout of timestamps passed to queries, stored timestamps are UTC
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Get - returns the productivity statistics of a user's tasks.
+// Days are the days of the location, the range is inclusive and at most two years.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param options - *StatsOptions
+//	@param location - the timezone of the user
+//	@return *StatsResponse
+//	@return error
+func Get(ctx context.Context, uid string, options *StatsOptions, location *time.Location) (*StatsResponse, error) {
+	// resolve the range in the timezone
+	now := time.Now().In(location)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	if len(options.To) > 0 {
+		t, err := time.ParseInLocation("2006-01-02", options.To, location)
+		if err != nil {
+			return nil, ErrInvalidRange
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, 1-defaultDays)
+	if len(options.From) > 0 {
+		t, err := time.ParseInLocation("2006-01-02", options.From, location)
+		if err != nil {
+			return nil, ErrInvalidRange
+		}
+		from = t
+	}
+	if to.Before(from) || to.Sub(from) > maxDays*24*time.Hour {
+		return nil, ErrInvalidRange
+	}
+
+	interval := options.Interval
+	if len(interval) < 1 {
+		interval = "day"
+	}
+
+	data := map[string]any{
+		"uid":      uid,
+		"timezone": location.String(),
+		"interval": interval,
+		"step":     fmt.Sprintf("1 %v", interval),
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		// the utc bounds of the local days, the end is exclusive
+		"start": from.UTC().Format(timestampLayout),
+		"end":   to.AddDate(0, 0, 1).UTC().Format(timestampLayout),
+		"now":   time.Now().UTC().Format(timestampLayout),
+	}
+
+	response := &StatsResponse{
+		From:       data["from"].(string),
+		To:         data["to"].(string),
+		Timezone:   location.String(),
+		Interval:   interval,
+		Series:     []Bucket{},
+		ByCategory: []Count{},
+		ByStatus:   []Count{},
+		ByColor:    []Count{},
+	}
+
+	// created and completed per interval, intervals without tasks are zero
+	seriesQuery := `
+    WITH created AS (
+      SELECT date_trunc(:interval, (created_at AT TIME ZONE 'UTC') AT TIME ZONE :timezone) AS start, COUNT(*) AS count
+      FROM tasks
+      WHERE uid = :uid AND created_at >= CAST(:start AS TIMESTAMP) AND created_at < CAST(:end AS TIMESTAMP)
+      GROUP BY 1
+    ),
+    completed AS (
+      SELECT date_trunc(:interval, (completed_at AT TIME ZONE 'UTC') AT TIME ZONE :timezone) AS start, COUNT(*) AS count
+      FROM tasks
+      WHERE uid = :uid AND completed AND completed_at >= CAST(:start AS TIMESTAMP) AND completed_at < CAST(:end AS TIMESTAMP)
+      GROUP BY 1
+    ),
+    buckets AS (
+      SELECT generate_series(
+        date_trunc(:interval, CAST(:from AS TIMESTAMP)), CAST(:to AS TIMESTAMP), CAST(:step AS INTERVAL)
+      ) AS start
+    )
+    SELECT
+      to_char(buckets.start, 'YYYY-MM-DD') AS start,
+      COALESCE(created.count, 0) AS created,
+      COALESCE(completed.count, 0) AS completed
+    FROM buckets
+    LEFT JOIN created ON created.start = buckets.start
+    LEFT JOIN completed ON completed.start = buckets.start
+    ORDER BY buckets.start
+  `
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, seriesQuery, data, &response.Series); err != nil {
+		return nil, fmt.Errorf("selecting series: %w", err)
+	}
+
+	// totals
+	summaryQuery := `
+    SELECT
+      COUNT(*) FILTER (WHERE created_at >= CAST(:start AS TIMESTAMP) AND created_at < CAST(:end AS TIMESTAMP)) AS created,
+      COUNT(*) FILTER (WHERE completed AND completed_at >= CAST(:start AS TIMESTAMP) AND completed_at < CAST(:end AS TIMESTAMP)) AS completed,
+      COUNT(*) FILTER (WHERE completed AND created_at >= CAST(:start AS TIMESTAMP) AND created_at < CAST(:end AS TIMESTAMP)) AS created_completed,
+      COALESCE(
+        AVG(EXTRACT(EPOCH FROM completed_at - created_at))
+          FILTER (WHERE completed AND completed_at >= CAST(:start AS TIMESTAMP) AND completed_at < CAST(:end AS TIMESTAMP)),
+        0
+      ) AS average_time_to_complete,
+      COUNT(*) FILTER (WHERE NOT completed AND NOT archived AND due_at < CAST(:now AS TIMESTAMP)) AS overdue
+    FROM tasks
+    WHERE uid = :uid
+  `
+	var totals summary
+	if err := database.NamedStructQuery(ctx, tasksDatabase, summaryQuery, data, &totals); err != nil {
+		return nil, fmt.Errorf("selecting totals: %w", err)
+	}
+
+	response.Created = totals.Created
+	response.Completed = totals.Completed
+	response.AverageTimeToComplete = totals.AverageTimeToComplete
+	response.Overdue = totals.Overdue
+	if totals.Created > 0 {
+		response.CompletionRate = float64(totals.CreatedCompleted) / float64(totals.Created)
+	}
+
+	// the breakdowns in one pass
+	breakdownsQuery := `
+    SELECT
+      CASE WHEN GROUPING(category) = 0 THEN 'category' WHEN GROUPING(status) = 0 THEN 'status' ELSE 'color' END AS dimension,
+      COALESCE(category, status, color) AS key,
+      COUNT(*) AS count
+    FROM (
+      SELECT category, status, COALESCE(NULLIF(color, ''), 'default') AS color
+      FROM tasks
+      WHERE uid = :uid AND created_at >= CAST(:start AS TIMESTAMP) AND created_at < CAST(:end AS TIMESTAMP)
+    ) AS created
+    GROUP BY GROUPING SETS ((category), (status), (color))
+    ORDER BY dimension, count DESC, key
+  `
+	var breakdowns []breakdown
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, breakdownsQuery, data, &breakdowns); err != nil {
+		return nil, fmt.Errorf("selecting breakdowns: %w", err)
+	}
+
+	for _, b := range breakdowns {
+		count := Count{Key: b.Key, Count: b.Count}
+		switch b.Dimension {
+		case "category":
+			response.ByCategory = append(response.ByCategory, count)
+		case "status":
+			response.ByStatus = append(response.ByStatus, count)
+		default:
+			response.ByColor = append(response.ByColor, count)
+		}
+	}
+
+	return response, nil
+}
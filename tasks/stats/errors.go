@@ -0,0 +1,8 @@
+package stats
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrInvalidRange - the range ends before it starts or is too long
+	ErrInvalidRange = &errs.Error{Code: errs.InvalidArgument, Message: "invalid date range"}
+)
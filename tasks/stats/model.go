@@ -0,0 +1,53 @@
+package stats
+
+type StatsOptions struct {
+	From     string `json:"from" validate:"omitempty,datetime=2006-01-02"` // first day, default: 29 days before the last day
+	To       string `json:"to" validate:"omitempty,datetime=2006-01-02"`   // last day (inclusive), default: today
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`        // default: the user's timezone
+	Interval string `json:"interval" validate:"omitempty,oneof=day week"`  // the series bucket size, default: "day"
+}
+
+type StatsResponse struct {
+	From                  string   `json:"from"`
+	To                    string   `json:"to"`
+	Timezone              string   `json:"timezone"`
+	Interval              string   `json:"interval"`
+	Series                []Bucket `json:"series"`                // tasks created and completed per interval
+	Created               int      `json:"created"`               // tasks created in the range
+	Completed             int      `json:"completed"`             // tasks completed in the range
+	CompletionRate        float64  `json:"completionRate"`        // share of the tasks created in the range that are completed, 0-1
+	AverageTimeToComplete float64  `json:"averageTimeToComplete"` // seconds from creation to completion of the tasks completed in the range
+	Overdue               int      `json:"overdue"`               // open tasks past their due date now
+	ByCategory            []Count  `json:"byCategory"`            // tasks created in the range per category
+	ByStatus              []Count  `json:"byStatus"`              // tasks created in the range per status
+	ByColor               []Count  `json:"byColor"`               // tasks created in the range per color
+}
+
+// Bucket - the counts of an interval of the series
+type Bucket struct {
+	Start     string `json:"start" db:"start"` // the first day of the interval
+	Created   int    `json:"created" db:"created"`
+	Completed int    `json:"completed" db:"completed"`
+}
+
+// Count - the number of tasks with a value
+type Count struct {
+	Key   string `json:"key" db:"key"`
+	Count int    `json:"count" db:"count"`
+}
+
+// summary - the row of the totals query
+type summary struct {
+	Created               int     `db:"created"`
+	Completed             int     `db:"completed"`
+	CreatedCompleted      int     `db:"created_completed"` // tasks created in the range which are completed
+	AverageTimeToComplete float64 `db:"average_time_to_complete"`
+	Overdue               int     `db:"overdue"`
+}
+
+// breakdown - a row of the breakdowns query
+type breakdown struct {
+	Dimension string `db:"dimension"` // category, status or color
+	Key       string `db:"key"`
+	Count     int    `db:"count"`
+}
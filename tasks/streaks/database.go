@@ -0,0 +1,150 @@
+package streaks
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+)
+
+// get the service name
+var streaksDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// dateLayout - the layout of days
+const dateLayout = "2006-01-02"
+
+// Refresh - Refresh recalculates the streaks of a user from their completed tasks.
+// Days are the days of the location. It returns the milestone the current streak reached,
+// 0 when no new milestone was reached.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param location - the timezone of the user
+//	@return *StreakResponse
+//	@return int
+//	@return error
+func Refresh(ctx context.Context, uid string, location *time.Location) (*StreakResponse, int, error) {
+	var response *StreakResponse
+	reached := 0
+
+	err := database.Transaction(ctx, streaksDatabase, func(tx *sqlx.Tx) error {
+		streak, err := lock(ctx, tx, uid)
+		if err != nil {
+			return err
+		}
+
+		// the completions per day in the timezone
+		query := `
+      SELECT to_char((completed_at AT TIME ZONE 'UTC') AT TIME ZONE :timezone, 'YYYY-MM-DD') AS day, COUNT(*) AS count
+      FROM tasks
+      WHERE uid = :uid AND completed
+      GROUP BY 1
+      ORDER BY 1 ASC
+    `
+		var days []day
+		if err := database.NamedSliceQuery(ctx, tx, query, map[string]any{
+			"uid":      uid,
+			"timezone": location.String(),
+		}, &days); err != nil {
+			return fmt.Errorf("selecting completions: %w", err)
+		}
+
+		now := time.Now().In(location)
+		today := now.Format(dateLayout)
+		response = calculate(days, streak.DailyGoal, now)
+		response.Timezone = location.String()
+
+		// a milestone is reached once per streak
+		startedOn := ""
+		if streak.StartedOn != nil {
+			startedOn = streak.StartedOn.Format(dateLayout)
+		}
+		milestone := streak.Milestone
+		if startedOn != response.StartedOn {
+			milestone = 0
+		}
+		if m := lastMilestone(response.Current); m > milestone && response.LastMetOn == today {
+			milestone, reached = m, m
+		}
+
+		// query statement to be executed
+		q := `
+      UPDATE streaks SET
+        current_streak = :current_streak, longest_streak = :longest_streak,
+        started_on = CAST(NULLIF(:started_on, '') AS DATE), last_met_on = CAST(NULLIF(:last_met_on, '') AS DATE),
+        milestone = :milestone, updated_at = :updated_at
+      WHERE uid = :uid
+    `
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, q, map[string]any{
+			"uid":            uid,
+			"current_streak": response.Current,
+			"longest_streak": response.Longest,
+			"started_on":     response.StartedOn,
+			"last_met_on":    response.LastMetOn,
+			"milestone":      milestone,
+			"updated_at":     time.Now().UTC(),
+		}); err != nil {
+			return fmt.Errorf("updating streak: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return response, reached, nil
+}
+
+// SetGoal - SetGoal sets the number of tasks a user wants to complete a day.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param goal - int
+//	@return error
+func SetGoal(ctx context.Context, uid string, goal int) error {
+	// query statement to be executed
+	q := `
+    INSERT INTO streaks (uid, daily_goal, updated_at) VALUES (:uid, :daily_goal, :updated_at)
+    ON CONFLICT (uid) DO UPDATE SET daily_goal = EXCLUDED.daily_goal, updated_at = EXCLUDED.updated_at
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, streaksDatabase, q, map[string]any{
+		"uid":        uid,
+		"daily_goal": goal,
+		"updated_at": time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("updating daily goal: %w", err)
+	}
+
+	return nil
+}
+
+// lock - returns the streak row of a user locked for update, creating it with the defaults.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param uid - string
+//	@return *Streak
+//	@return error
+func lock(ctx context.Context, tx *sqlx.Tx, uid string) (*Streak, error) {
+	data := map[string]any{"uid": uid}
+
+	if err := database.NamedExecQuery(ctx, tx, "INSERT INTO streaks (uid) VALUES (:uid) ON CONFLICT (uid) DO NOTHING", data); err != nil {
+		return nil, fmt.Errorf("inserting streak: %w", err)
+	}
+
+	var streak Streak
+	if err := database.NamedStructQuery(ctx, tx, "SELECT * FROM streaks WHERE uid = :uid FOR UPDATE", data, &streak); err != nil {
+		return nil, fmt.Errorf("selecting streak: %w", err)
+	}
+
+	return &streak, nil
+}
@@ -0,0 +1,39 @@
+package streaks
+
+import "time"
+
+// milestones - the streak lengths, in days, that publish an event
+var milestones = []int{3, 7, 14, 30, 50, 100, 200, 365, 500, 1000}
+
+type Streak struct {
+	UserID    string     `json:"uid" db:"uid"`
+	DailyGoal int        `json:"dailyGoal" db:"daily_goal"`   // tasks to complete a day, default: 1
+	Current   int        `json:"current" db:"current_streak"` // days in a row the goal was met, up to today or yesterday
+	Longest   int        `json:"longest" db:"longest_streak"` // the longest run of days the goal was met
+	StartedOn *time.Time `json:"startedOn" db:"started_on"`   // the first day of the current streak
+	LastMetOn *time.Time `json:"lastMetOn" db:"last_met_on"`  // the last day the goal was met
+	Milestone int        `json:"milestone" db:"milestone"`    // the last milestone reached in the current streak
+	UpdatedAt time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type StreakResponse struct {
+	DailyGoal      int    `json:"dailyGoal"`
+	CompletedToday int    `json:"completedToday"`
+	GoalMetToday   bool   `json:"goalMetToday"`
+	Current        int    `json:"current"`
+	Longest        int    `json:"longest"`
+	StartedOn      string `json:"startedOn"` // empty when there is no current streak
+	LastMetOn      string `json:"lastMetOn"` // empty when the goal was never met
+	NextMilestone  int    `json:"nextMilestone"`
+	Timezone       string `json:"timezone"`
+}
+
+type UpdateGoalPayload struct {
+	DailyGoal int `json:"dailyGoal" validate:"required,min=1,max=100"` // required
+}
+
+// day - the number of tasks completed on a day
+type day struct {
+	Day   string `db:"day"` // YYYY-MM-DD in the user's timezone
+	Count int    `db:"count"`
+}
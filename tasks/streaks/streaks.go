@@ -0,0 +1,91 @@
+package streaks
+
+import "time"
+
+// calculate - returns the streaks of the days on which tasks were completed.
+// The current streak is the run of days meeting the goal that ends today, or yesterday while today is not over.
+//
+//	@param days - the completions per day, in ascending order
+//	@param goal - the tasks to complete a day
+//	@param now - the current time in the user's timezone
+//	@return *StreakResponse
+func calculate(days []day, goal int, now time.Time) *StreakResponse {
+	today := now.Format(dateLayout)
+	yesterday := now.AddDate(0, 0, -1).Format(dateLayout)
+
+	response := &StreakResponse{DailyGoal: goal}
+
+	// find the runs of consecutive days meeting the goal
+	run, start, previous := 0, "", ""
+	for _, d := range days {
+		if d.Day == today {
+			response.CompletedToday = d.Count
+		}
+		if d.Count < goal {
+			continue
+		}
+
+		if len(previous) > 0 && nextDay(previous) == d.Day {
+			run++
+		} else {
+			run, start = 1, d.Day
+		}
+		previous = d.Day
+
+		if run > response.Longest {
+			response.Longest = run
+		}
+	}
+
+	response.LastMetOn = previous
+	response.GoalMetToday = previous == today
+
+	// the last run is current while it reaches today or yesterday
+	if previous == today || previous == yesterday {
+		response.Current = run
+		response.StartedOn = start
+	}
+
+	response.NextMilestone = nextMilestone(response.Current)
+
+	return response
+}
+
+// nextDay - returns the day after a day.
+//
+//	@param d - YYYY-MM-DD
+//	@return string
+func nextDay(d string) string {
+	t, err := time.Parse(dateLayout, d)
+	if err != nil {
+		return ""
+	}
+	return t.AddDate(0, 0, 1).Format(dateLayout)
+}
+
+// lastMilestone - returns the largest milestone a streak reached, 0 when none.
+//
+//	@param streak - int
+//	@return int
+func lastMilestone(streak int) int {
+	reached := 0
+	for _, m := range milestones {
+		if streak >= m {
+			reached = m
+		}
+	}
+	return reached
+}
+
+// nextMilestone - returns the smallest milestone a streak has not reached, 0 when all are reached.
+//
+//	@param streak - int
+//	@return int
+func nextMilestone(streak int) int {
+	for _, m := range milestones {
+		if streak < m {
+			return m
+		}
+	}
+	return 0
+}
@@ -2,15 +2,40 @@ package tasks
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"time"
 
+	"encore.dev"
+	"encore.dev/beta/auth"
+	"encore.dev/beta/errs"
+	"encore.dev/cron"
 	"encore.dev/pubsub"
+	"encore.dev/rlog"
 	"github.com/go-playground/validator/v10"
 
+	"encore.app/pkg/color"
 	"encore.app/pkg/events"
+	"encore.app/pkg/middleware"
 	"encore.app/pkg/pagination"
+	"encore.app/tasks/caldav"
 	"encore.app/tasks/cs"
+	"encore.app/tasks/defaults"
+	"encore.app/tasks/delta"
+	"encore.app/tasks/feed"
+	"encore.app/tasks/filters"
+	"encore.app/tasks/live"
+	"encore.app/tasks/quickadd"
+	"encore.app/tasks/snooze"
+	"encore.app/tasks/stats"
+	"encore.app/tasks/streaks"
+	"encore.app/tasks/transfer"
 	"encore.app/tasks/ts"
+	"encore.app/tasks/undo"
+	"encore.app/tasks/webhooks"
 	"encore.app/users"
 )
 
@@ -29,15 +54,19 @@ func CreateTask(ctx context.Context, uid string, payload *ts.CreateTaskPayload)
 	}
 
 	// check if user exists
-	if user, err := users.Get(ctx, uid); err != nil || user == nil || user.ID != uid {
+	if user, err := users.Get(ctx, uid, &pagination.Query{}); err != nil || user == nil || user.ID != uid {
 		return err
 	}
 
 	// create task
-	if err := ts.Create(ctx, uid, payload); err != nil {
+	task, err := ts.Create(ctx, uid, payload)
+	if err != nil {
 		return err
 	}
 
+	_, err = events.TaskCreated.Publish(ctx, &events.TaskCreatedEvent{Task: task.Event()})
+	logPublish("task-created", err)
+
 	return nil
 }
 
@@ -45,13 +74,14 @@ func CreateTask(ctx context.Context, uid string, payload *ts.CreateTaskPayload)
 //
 // @param ctx - context.Context
 // @param id - string
+// @param query - *pagination.Query
 // @return task
 // @return error
 //
 // encore:api public method=GET path=/tasks/get/:id
-func GetTask(ctx context.Context, id string) (*ts.Task, error) {
+func GetTask(ctx context.Context, id string, query *pagination.Query) (*ts.Task, error) {
 	// get task
-	task, err := ts.Get(ctx, id)
+	task, err := ts.Get(ctx, id, query.Fields)
 	if err != nil {
 		return nil, err
 	}
@@ -64,89 +94,218 @@ func GetTask(ctx context.Context, id string) (*ts.Task, error) {
 // @param ctx - context.Context
 // @param id - string
 // @param payload
-// @return task
+// @return undo token
 // @return error
 //
 // encore:api auth method=PATCH path=/tasks/update/:id
-func UpdateTask(ctx context.Context, id string, payload *ts.UpdateTaskPayload) error {
+func UpdateTask(ctx context.Context, id string, payload *ts.UpdateTaskPayload) (*undo.UndoResponse, error) {
 	// validate payload
 	if err := validator.New().Struct(payload); err != nil {
-		return err
+		return nil, err
+	}
+
+	// get the task before the change
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// update task
 	if err := ts.Update(ctx, id, payload); err != nil {
-		return err
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	// publish the changed fields
+	task, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if changed := ts.Changes(before, task); len(changed) > 0 {
+		_, err = events.TaskUpdated.Publish(ctx, &events.TaskUpdatedEvent{Task: task.Event(), Changed: changed})
+		logPublish("task-updated", err)
+	}
+
+	return recordUndo(ctx, undo.OpUpdateTask, undo.TaskEntry(before, task)), nil
 }
 
 // Delete - Delete a task
 //
 // @param ctx - context.Context
 // @param id - string
-// @return task
+// @return undo token
 // @return error
 //
 // encore:api auth method=DELETE path=/tasks/delete/:id
-func DeleteTask(ctx context.Context, id string) error {
+func DeleteTask(ctx context.Context, id string) (*undo.UndoResponse, error) {
+	// get the task for the event
+	task, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+
 	// delete task
 	if err := ts.Delete(ctx, id); err != nil {
-		return err
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	_, err = events.TaskDeleted.Publish(ctx, &events.TaskDeletedEvent{Task: task.Event()})
+	logPublish("task-deleted", err)
+
+	return recordUndo(ctx, undo.OpDeleteTask, undo.TaskEntry(task, nil)), nil
 }
 
 // ToggleMultipleComplete - Toggle multiple tasks' complete status
 //
 // @param ctx - context.Context
 // @param {*ts.ToggleMultipleTasksCompletePayload} ids - ids of tasks to toggle
+// @return undo token
 // @return error
 //
 // encore:api auth method=PATCH path=/tasks/toggle/all/complete
-func ToggleMultipleTaskComplete(ctx context.Context, ids *ts.MultiIdsPayload) error {
+func ToggleMultipleTaskComplete(ctx context.Context, ids *ts.MultiIdsPayload) (*undo.UndoResponse, error) {
+	// get the tasks before the change
+	before, err := ts.GetMany(ctx, ids.Ids)
+	if err != nil {
+		return nil, err
+	}
+
 	// toggle complete
 	if err := ts.ToggleMultipleComplete(ctx, ids.Ids); err != nil {
-		return err
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	// update the streak of the caller, the streaks of other users can only be read with their own claims
+	tasks, err := ts.GetMany(ctx, ids.Ids)
+	if err != nil {
+		return nil, err
+	}
+	refreshed := false
+	for i, task := range tasks {
+		if !refreshed && authorizeUser(ctx, task.UserID) == nil {
+			refreshed = true
+			refreshStreak(ctx, task.UserID)
+		}
+		publishCompletion(ctx, &tasks[i])
+	}
+
+	// the previous state of the toggled tasks
+	after := map[string]*ts.Task{}
+	for i := range tasks {
+		after[tasks[i].ID] = &tasks[i]
+	}
+	entries := []undo.Entry{}
+	for i := range before {
+		if task, ok := after[before[i].ID]; ok {
+			entries = append(entries, undo.TaskEntry(&before[i], task))
+		}
+	}
+
+	return recordUndo(ctx, undo.OpToggleTasks, entries...), nil
 }
 
 // ToggleComplete - Toggle a task's complete status
 //
 // @param ctx - context.Context
 // @param id - string
-// @return task
+// @return undo token
 // @return error
 //
 // encore:api auth method=PATCH path=/tasks/toggle/complete/:id
-func ToggleTaskComplete(ctx context.Context, id string) error {
+func ToggleTaskComplete(ctx context.Context, id string) (*undo.UndoResponse, error) {
+	// get the task before the change
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+
 	// toggle complete
-	if err := ts.ToggleComplete(ctx, id); err != nil {
-		return err
+	task, err := ts.ToggleComplete(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	// update the streaks of the owner
+	refreshStreak(ctx, task.UserID)
+	publishCompletion(ctx, task)
+
+	return recordUndo(ctx, undo.OpToggleTask, undo.TaskEntry(before, task)), nil
+}
+
+// ToggleArchive - Archive or unarchive a task
+//
+// @param ctx - context.Context
+// @param id - string
+// @return undo token
+// @return error
+//
+// encore:api auth method=PATCH path=/tasks/toggle/archive/:id
+func ToggleTaskArchive(ctx context.Context, id string) (*undo.UndoResponse, error) {
+	// get the task before the change
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// toggle archive
+	task, err := ts.ToggleArchive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if task.Archived {
+		_, err = events.TaskArchived.Publish(ctx, &events.TaskArchivedEvent{Task: task.Event()})
+		logPublish("task-archived", err)
+	} else {
+		_, err = events.TaskUpdated.Publish(ctx, &events.TaskUpdatedEvent{Task: task.Event(), Changed: []string{"archived"}})
+		logPublish("task-updated", err)
+	}
+
+	return recordUndo(ctx, undo.OpArchiveTask, undo.TaskEntry(before, task)), nil
+}
+
+// ToggleChecklistItem - Check or uncheck a task list item ("- [ ] item") of a task's markdown description
+//
+// @param ctx - context.Context
+// @param id - string
+// @param index - the position of the item in the description, from 0
+// @return task
+// @return error
+//
+// encore:api auth method=PATCH path=/tasks/toggle/checklist/:id/:index
+func ToggleChecklistItem(ctx context.Context, id string, index int) (*ts.Task, error) {
+	// only the owner of the task may change it
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUser(ctx, before.UserID); err != nil {
+		return nil, err
+	}
+
+	// toggle the item
+	task, err := ts.ToggleChecklistItem(ctx, id, index)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = events.TaskUpdated.Publish(ctx, &events.TaskUpdatedEvent{Task: task.Event(), Changed: []string{"description"}})
+	logPublish("task-updated", err)
+
+	return task, nil
 }
 
-// GetUserTasks - Get all tasks for a user
+// GetUserTasks - Get all tasks for a user, snoozed tasks only when includeSnoozed is set
 //
 // @param ctx - context.Context
 // @param uid - string
+// @param options - *ts.ListOptions
 // @return tasks
 // @return error
 //
 // encore:api auth method=GET path=/users/:uid/tasks
-func GetUserTasks(ctx context.Context, uid string, options *pagination.Options) (*ts.PaginatedTasksResponse, error) {
+func GetUserTasks(ctx context.Context, uid string, options *ts.ListOptions) (*ts.PaginatedTasksResponse, error) {
 	// get user tasks
-	tasks, err := ts.GetUserTasks(ctx, uid, options)
+	tasks, err := ts.GetUserTasks(ctx, uid, options.Pagination(), options.IncludeSnoozed)
 	if err != nil {
 		return nil, fmt.Errorf("querying tasks: %w", err)
 	}
@@ -159,18 +318,33 @@ func GetUserTasks(ctx context.Context, uid string, options *pagination.Options)
 //
 // @param ctx - context.Context
 // @param uid - string
-// @return task
+// @return undo token
 // @return error
 //
 // encore:api auth method=DELETE path=/users/:uid/tasks/delete
-func DeleteAllTasksWithUserID(ctx context.Context, uid string) error {
+func DeleteAllTasksWithUserID(ctx context.Context, uid string) (*undo.UndoResponse, error) {
+	// keep the tasks to undo the delete and to publish their deletes
+	tasks := []ts.Task{}
+	entries := []undo.Entry{}
+	if err := ts.StreamUserTasks(ctx, uid, &pagination.Options{}, false, func(task ts.Task) error {
+		tasks = append(tasks, task)
+		entries = append(entries, undo.TaskEntry(&task, nil))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
 	// delete tasks
 	if err := ts.DeleteAllWithUserID(ctx, uid); err != nil {
-		return err
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	for i := range tasks {
+		_, err := events.TaskDeleted.Publish(ctx, &events.TaskDeletedEvent{Task: tasks[i].Event()})
+		logPublish("task-deleted", err)
+	}
+
+	return recordUndo(ctx, undo.OpDeleteAllTasks, entries...), nil
 }
 
 // SUBSCRIPTIONS - Subscriptions to delete all tasks for a user
@@ -182,7 +356,8 @@ var _ = pubsub.NewSubscription(
 	"delete-all-tasks-with-user-id",
 	pubsub.SubscriptionConfig[*events.DeleteAllUserTasksEvent]{
 		Handler: func(ctx context.Context, event *events.DeleteAllUserTasksEvent) error {
-			return DeleteAllTasksWithUserID(ctx, event.UserID)
+			_, err := DeleteAllTasksWithUserID(ctx, event.UserID)
+			return err
 		},
 	},
 )
@@ -204,10 +379,14 @@ func CreateCategory(ctx context.Context, uid string, payload *cs.CreateCategoryP
 	}
 
 	// create category
-	if err := cs.Create(ctx, uid, payload); err != nil {
+	category, err := cs.Create(ctx, uid, payload)
+	if err != nil {
 		return err
 	}
 
+	_, err = events.CategoryCreated.Publish(ctx, &events.CategoryCreatedEvent{Category: category.Event()})
+	logPublish("category-created", err)
+
 	return nil
 }
 
@@ -215,13 +394,14 @@ func CreateCategory(ctx context.Context, uid string, payload *cs.CreateCategoryP
 //
 //	@param ctx - context.Context
 //	@param id - string
+//	@param query - *pagination.Query
 //	@return category
 //	@return error
 //
 // encore:api public method=GET path=/categories/:id
-func GetCategory(ctx context.Context, id string) (*cs.Category, error) {
+func GetCategory(ctx context.Context, id string, query *pagination.Query) (*cs.Category, error) {
 	// get category
-	category, err := cs.Get(ctx, id)
+	category, err := cs.Get(ctx, id, query.Fields)
 	if err != nil {
 		return nil, err
 	}
@@ -234,53 +414,157 @@ func GetCategory(ctx context.Context, id string) (*cs.Category, error) {
 //	@param ctx - context.Context
 //	@param id - string
 //	@param payload
-//	@return category
+//	@return undo token
 //	@return error
 //
 // encore:api auth method=PATCH path=/categories/:id
-func UpdateCategory(ctx context.Context, id string, payload *cs.UpdateCategoryPayload) error {
+func UpdateCategory(ctx context.Context, id string, payload *cs.UpdateCategoryPayload) (*undo.UndoResponse, error) {
 	// validate payload
 	if err := validator.New().Struct(payload); err != nil {
-		return err
+		return nil, err
+	}
+
+	// get the category before the change
+	before, err := cs.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
 	}
 
 	// update category
 	if err := cs.Update(ctx, id, payload); err != nil {
-		return err
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	// publish the changed fields
+	category, err := cs.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if changed := cs.Changes(before, category); len(changed) > 0 {
+		_, err = events.CategoryUpdated.Publish(ctx, &events.CategoryUpdatedEvent{Category: category.Event(), Changed: changed})
+		logPublish("category-updated", err)
+	}
+
+	return recordUndo(ctx, undo.OpUpdateCategory, undo.CategoryEntry(before, category)), nil
 }
 
 // Delete - Delete a category
+// Its tasks are left without a category unless they are reassigned to another category or deleted.
+// The categories nested in it move up to its parent.
 //
 //	@param ctx - context.Context
 //	@param id - string
+//	@param options - *cs.DeleteCategoryOptions
+//	@return undo token
 //	@return error
 //
 // encore:api auth method=DELETE path=/categories/:id
-func DeleteCategory(ctx context.Context, id string) error {
+func DeleteCategory(ctx context.Context, id string, options *cs.DeleteCategoryOptions) (*undo.UndoResponse, error) {
+	// validate options
+	if err := validator.New().Struct(options); err != nil {
+		return nil, err
+	}
+
+	// get the category and its tasks for the events
+	category, err := cs.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := ts.FindManyByField(ctx, "category_id", "=", id)
+	if err != nil && err != ts.ErrNotFound {
+		return nil, err
+	}
+	children, err := cs.FindManyByField(ctx, "parent_id", "=", id)
+	if err != nil && err != cs.ErrNotFound {
+		return nil, err
+	}
+
 	// delete category
-	if err := cs.Delete(ctx, id); err != nil {
-		return err
+	if err := cs.Delete(ctx, id, options); err != nil {
+		return nil, err
 	}
 
-	// return nil if no error
-	return nil
+	_, err = events.CategoryDeleted.Publish(ctx, &events.CategoryDeletedEvent{Category: category.Event()})
+	logPublish("category-deleted", err)
+
+	// the category is restored before its children and tasks
+	entries := []undo.Entry{undo.CategoryEntry(category, nil)}
+	for i := range children {
+		moved, err := cs.Get(ctx, children[i].ID, "")
+		if err != nil {
+			rlog.Error("selecting nested category failed", "id", children[i].ID, "err", err)
+			continue
+		}
+		publishCategoryChange(ctx, &children[i], moved)
+		entries = append(entries, undo.CategoryEntry(&children[i], moved))
+	}
+	entries = append(entries, publishCategoryTasks(ctx, tasks)...)
+
+	return recordUndo(ctx, undo.OpDeleteCategory, entries...), nil
+}
+
+// publishCategoryTasks - publishes the changes of the tasks of deleted categories and returns their undo entries.
+//
+//	@param ctx - context.Context
+//	@param tasks - the tasks before the categories were deleted
+//	@return []undo.Entry
+func publishCategoryTasks(ctx context.Context, tasks []ts.Task) []undo.Entry {
+	entries := []undo.Entry{}
+	if len(tasks) < 1 {
+		return entries
+	}
+
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	current, err := ts.GetMany(ctx, ids)
+	if err != nil {
+		rlog.Error("selecting tasks of deleted categories failed", "err", err)
+		return entries
+	}
+	after := map[string]*ts.Task{}
+	for i := range current {
+		after[current[i].ID] = &current[i]
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if updated, ok := after[task.ID]; ok {
+			publishTaskChange(ctx, task, updated)
+			entries = append(entries, undo.TaskEntry(task, updated))
+			continue
+		}
+		_, err := events.TaskDeleted.Publish(ctx, &events.TaskDeletedEvent{Task: task.Event()})
+		logPublish("task-deleted", err)
+		entries = append(entries, undo.TaskEntry(task, nil))
+	}
+
+	return entries
 }
 
-// GetUserCategories - Get all categories for a user
+// GetUserCategories - Get all categories for a user in their order, with the number of open and completed tasks in each
+// With the tree option, all categories are returned nested in their parents.
 //
 //	@param ctx - context.Context
 //	@param uid - string
+//	@param options - *cs.ListOptions
 //	@return categories
 //	@return error
 //
 // encore:api auth method=GET path=/users/:uid/categories
-func GetUserCategories(ctx context.Context, uid string, options *pagination.Options) (*cs.PaginatedCategoriesResponse, error) {
+func GetUserCategories(ctx context.Context, uid string, options *cs.ListOptions) (*cs.PaginatedCategoriesResponse, error) {
+	// get the tree of user categories
+	if options.Tree {
+		roots, count, err := cs.GetUserCategoryTree(ctx, uid, options.Pagination())
+		if err != nil {
+			return nil, fmt.Errorf("querying categories: %w", err)
+		}
+		return &cs.PaginatedCategoriesResponse{Categories: roots, Total: count, TotalPages: 1, CurrentPage: 1}, nil
+	}
+
 	// get user categories
-	categories, err := cs.GetUserCategories(ctx, uid, options)
+	categories, err := cs.GetUserCategories(ctx, uid, options.Pagination())
 	if err != nil {
 		return nil, fmt.Errorf("querying categories: %w", err)
 	}
@@ -289,21 +573,1721 @@ func GetUserCategories(ctx context.Context, uid string, options *pagination.Opti
 	return categories, nil
 }
 
+// ReorderCategories - Set the order of a user's categories
+// Categories left out of the ids follow the given ones in their current order.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *cs.ReorderCategoriesPayload
+//	@return *cs.CategoriesResponse - all categories in their new order
+//	@return error
+//
+// encore:api auth method=PUT path=/users/:uid/categories/order
+func ReorderCategories(ctx context.Context, uid string, payload *cs.ReorderCategoriesPayload) (*cs.CategoriesResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// reorder categories
+	moved, err := cs.Reorder(ctx, uid, payload.IDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range moved {
+		_, err := events.CategoryUpdated.Publish(ctx, &events.CategoryUpdatedEvent{Category: moved[i].Event(), Changed: []string{"position"}})
+		logPublish("category-updated", err)
+	}
+
+	// return the categories in their new order
+	response := &cs.CategoriesResponse{Categories: []cs.Category{}}
+	if err := cs.StreamUserCategories(ctx, uid, &pagination.Options{}, func(category cs.Category) error {
+		response.Categories = append(response.Categories, category)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// MergeCategories - Move the tasks of categories to a target category and delete them, all at once
+// The categories nested in them move to the target.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *cs.MergeCategoriesPayload
+//	@return undo token
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/categories/merge
+func MergeCategories(ctx context.Context, uid string, payload *cs.MergeCategoriesPayload) (*undo.UndoResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// get the categories, the nested ones and the tasks for the events
+	sources, err := cs.GetMany(ctx, payload.SourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	target, err := cs.Get(ctx, payload.TargetID, "")
+	if err != nil {
+		return nil, err
+	}
+	nested := []cs.Category{*target}
+	tasks := []ts.Task{}
+	for _, id := range payload.SourceIDs {
+		children, err := cs.FindManyByField(ctx, "parent_id", "=", id)
+		if err != nil && err != cs.ErrNotFound {
+			return nil, err
+		}
+		nested = append(nested, children...)
+
+		found, err := ts.FindManyByField(ctx, "category_id", "=", id)
+		if err != nil && err != ts.ErrNotFound {
+			return nil, err
+		}
+		tasks = append(tasks, found...)
+	}
+
+	// merge categories
+	if err := cs.Merge(ctx, uid, payload.TargetID, payload.SourceIDs); err != nil {
+		return nil, err
+	}
+
+	// the deleted categories are restored before the moved categories and tasks
+	entries := []undo.Entry{}
+	for i := range sources {
+		_, err := events.CategoryDeleted.Publish(ctx, &events.CategoryDeletedEvent{Category: sources[i].Event()})
+		logPublish("category-deleted", err)
+		entries = append(entries, undo.CategoryEntry(&sources[i], nil))
+	}
+	for i := range nested {
+		moved, err := cs.Get(ctx, nested[i].ID, "")
+		if err != nil {
+			rlog.Error("selecting merged category failed", "id", nested[i].ID, "err", err)
+			continue
+		}
+		if changed := cs.Changes(&nested[i], moved); len(changed) > 0 {
+			publishCategoryChange(ctx, &nested[i], moved)
+			entries = append(entries, undo.CategoryEntry(&nested[i], moved))
+		}
+	}
+	entries = append(entries, publishCategoryTasks(ctx, tasks)...)
+
+	return recordUndo(ctx, undo.OpMergeCategories, entries...), nil
+}
+
 // DeleteAllUserCategories - Delete all categories for a user
 //
 //	@param ctx - context.Context
 //	@param uid - string
+//	@return undo token
 //	@return error
 //
 // encore:api auth method=DELETE path=/users/:uid/categories/delete
-func DeleteAllUserCategories(ctx context.Context, uid string) error {
-	// delete categories
+func DeleteAllUserCategories(ctx context.Context, uid string) (*undo.UndoResponse, error) {
+	// keep the categories and their tasks to undo the delete, parents are restored before their children
+	roots, _, err := cs.GetUserCategoryTree(ctx, uid, &pagination.Options{})
+	if err != nil {
+		return nil, err
+	}
+	entries := []undo.Entry{}
+	var add func(categories []cs.Category)
+	add = func(categories []cs.Category) {
+		for i := range categories {
+			category := categories[i]
+			category.Children = nil
+			entries = append(entries, undo.CategoryEntry(&category, nil))
+			add(categories[i].Children)
+		}
+	}
+	add(roots)
+	tasks := []ts.Task{}
+	if err := ts.StreamUserTasks(ctx, uid, &pagination.Options{}, false, func(task ts.Task) error {
+		if task.CategoryID != nil {
+			tasks = append(tasks, task)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	// delete categories, the tasks are left without one
 	if err := cs.DeleteAllUserCategories(ctx, uid); err != nil {
-		return err
+		return nil, err
 	}
+	entries = append(entries, publishCategoryTasks(ctx, tasks)...)
 
-	// return nil if no error
-	return nil
+	return recordUndo(ctx, undo.OpDeleteAllCategories, entries...), nil
+}
+
+// =====================================================================================================================
+// TRANSFER
+// =====================================================================================================================
+
+// ImportTasks - Import tasks and categories from a CSV, Todoist or Trello export
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *transfer.ImportPayload
+//	@return response
+//	@return error
+//
+// encore:api auth method=POST path=/tasks/:uid/import
+func ImportTasks(ctx context.Context, uid string, payload *transfer.ImportPayload) (*transfer.ImportResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// check if user exists
+	if user, err := users.Get(ctx, uid, &pagination.Query{}); err != nil || user == nil || user.ID != uid {
+		return nil, err
+	}
+
+	// import tasks
+	response, err := transfer.Import(ctx, uid, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ExportTasks - Stream all tasks of a user as CSV, newline-delimited JSON or a Markdown checklist
+//
+//	@route GET /users/:uid/tasks/export?format=csv|ndjson|markdown&sort=&order=&fields=
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//
+// encore:api auth raw method=GET path=/users/:uid/tasks/export
+func ExportTasks(w http.ResponseWriter, req *http.Request) {
+	exportHandler(w, req, "tasks", transfer.ExportTasks)
+}
+
+// ExportCategories - Stream all categories of a user as CSV or newline-delimited JSON
+//
+//	@route GET /users/:uid/categories/export?format=csv|ndjson&sort=&order=&fields=
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//
+// encore:api auth raw method=GET path=/users/:uid/categories/export
+func ExportCategories(w http.ResponseWriter, req *http.Request) {
+	exportHandler(w, req, "categories", transfer.ExportCategories)
+}
+
+// exportHandler - writes the export of a user's resources as an attachment.
+//
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//	@param name - the name of the exported file
+//	@param export - the function streaming the export
+func exportHandler(w http.ResponseWriter, req *http.Request, name string, export func(context.Context, io.Writer, string, string, *pagination.Options) error) {
+	uid := encore.CurrentRequest().PathParams.Get("uid")
+	if err := authorizeUser(req.Context(), uid); err != nil {
+		writeJSONErrorResponse(w, &errs.Error{Code: errs.PermissionDenied, Message: err.Error()})
+		return
+	}
+	query := req.URL.Query()
+
+	// get the content type of the format
+	format := query.Get("format")
+	contentType, err := transfer.ContentType(format)
+	if err != nil {
+		writeJSONErrorResponse(w, err)
+		return
+	}
+
+	// the sort order and fields of the export
+	options := &pagination.Options{
+		Sort:   query.Get("sort"),
+		Order:  query.Get("order"),
+		Fields: query.Get("fields"),
+	}
+
+	// wrap the writer so headers are only sent with the first byte
+	ew := &exportWriter{ResponseWriter: w, contentType: contentType, filename: fmt.Sprintf("%v.%v", name, format)}
+
+	// stream the export
+	if err := export(req.Context(), ew, uid, format, options); err != nil {
+		// the response can only be changed if nothing was written yet
+		if !ew.started {
+			writeJSONErrorResponse(w, err)
+			return
+		}
+		rlog.Error("export failed", "uid", uid, "format", format, "err", err)
+	}
+}
+
+// exportWriter - sets the export headers before the first write.
+type exportWriter struct {
+	http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+// Write - writes the headers on the first call and then the data.
+func (w *exportWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.Header().Set("Content-Type", w.contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// writeJSONErrorResponse writes the error as a JSON response with the status code of its error code.
+func writeJSONErrorResponse(w http.ResponseWriter, err error) {
+	// use the status of encore errors
+	statusCode := http.StatusInternalServerError
+	message := "an internal server error occurred"
+	var e *errs.Error
+	if errors.As(err, &e) {
+		statusCode = e.Code.HTTPStatus()
+		message = e.Message
+	}
+
+	response, _ := json.Marshal(map[string]any{
+		"message": message,
+		"code":    statusCode,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(response)
+}
+
+// =====================================================================================================================
+// FEED
+// =====================================================================================================================
+
+// GenerateFeedToken - Generate the secret token of a user's calendar feed
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return response
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/feed
+func GenerateFeedToken(ctx context.Context, uid string) (*feed.FeedTokenResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// generate token
+	response, err := feed.Generate(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RotateFeedToken - Replace the secret token of a user's calendar feed, the previous feed url stops working
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return response
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/feed/rotate
+func RotateFeedToken(ctx context.Context, uid string) (*feed.FeedTokenResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// rotate token
+	response, err := feed.Rotate(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RevokeFeedToken - Revoke the secret token of a user's calendar feed
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return error
+//
+// encore:api auth method=DELETE path=/users/:uid/feed
+func RevokeFeedToken(ctx context.Context, uid string) error {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return err
+	}
+
+	// revoke token
+	if err := feed.Revoke(ctx, uid); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Feed - Serve the tasks with a due date of the feed's user as an iCalendar
+// The secret token in the path authenticates the request.
+//
+//	@route GET /feeds/:token?category=&type=todo|event
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//
+// encore:api public raw method=GET path=/feeds/:token
+func Feed(w http.ResponseWriter, req *http.Request) {
+	token := encore.CurrentRequest().PathParams.Get("token")
+	query := req.URL.Query()
+
+	// find the user of the feed
+	uid, err := feed.FindUserByToken(req.Context(), token)
+	if err != nil {
+		writeJSONErrorResponse(w, err)
+		return
+	}
+
+	// write the calendar
+	ew := &exportWriter{ResponseWriter: w, contentType: "text/calendar; charset=utf-8", filename: "bookie.ics"}
+	if err := feed.Write(req.Context(), ew, uid, query["category"], query.Get("type") == "event"); err != nil {
+		if !ew.started {
+			writeJSONErrorResponse(w, err)
+			return
+		}
+		rlog.Error("writing feed failed", "uid", uid, "err", err)
+	}
+}
+
+// =====================================================================================================================
+// QUICK ADD
+// =====================================================================================================================
+
+// QuickAdd - Parse a line such as "Pay rent tomorrow 9am #personal !high every month" into a task
+// Only the parsed task is returned for preview, it is created when the payload confirms it.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *quickadd.QuickAddPayload
+//	@return *quickadd.QuickAddResponse
+//	@return error
+//
+// encore:api auth method=POST path=/tasks/:uid/quick-add
+func QuickAdd(ctx context.Context, uid string, payload *quickadd.QuickAddPayload) (*quickadd.QuickAddResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// resolve dates in the timezone of the user
+	location, err := userLocation(ctx, uid, payload.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// parse the text
+	task, matches, err := quickadd.Parse(payload.Text, time.Now().In(location))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &quickadd.QuickAddResponse{Task: *task, Matches: matches, Timezone: location.String()}
+
+	// create the task when confirmed
+	if payload.Confirm {
+		if err := validator.New().Struct(task); err != nil {
+			return nil, err
+		}
+		created, err := ts.Create(ctx, uid, task)
+		if err != nil {
+			return nil, err
+		}
+		_, err = events.TaskCreated.Publish(ctx, &events.TaskCreatedEvent{Task: created.Event()})
+		logPublish("task-created", err)
+		response.Created = true
+	}
+
+	return response, nil
+}
+
+// userLocation - returns the location of a timezone, the user's timezone when it is empty.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param timezone - string
+//	@return *time.Location
+//	@return error
+func userLocation(ctx context.Context, uid, timezone string) (*time.Location, error) {
+	// check if user exists
+	user, err := users.Get(ctx, uid, &pagination.Query{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(timezone) < 1 {
+		timezone = user.Timezone
+	}
+	if len(timezone) < 1 {
+		timezone = "UTC"
+	}
+
+	return time.LoadLocation(timezone)
+}
+
+// =====================================================================================================================
+// STATS
+// =====================================================================================================================
+
+// GetUserStats - Get the productivity statistics of a user's tasks
+// Tasks created and completed per day or week, completion rate, average time to complete, breakdowns and overdue tasks.
+//
+//	@route GET /users/:uid/stats?from=2006-01-02&to=2006-01-02&timezone=&interval=day|week
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param options - *stats.StatsOptions
+//	@return *stats.StatsResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/stats
+func GetUserStats(ctx context.Context, uid string, options *stats.StatsOptions) (*stats.StatsResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate options
+	if err := validator.New().Struct(options); err != nil {
+		return nil, err
+	}
+
+	// days are the days of the user
+	location, err := userLocation(ctx, uid, options.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.Get(ctx, uid, options, location)
+}
+
+// =====================================================================================================================
+// STREAKS
+// =====================================================================================================================
+
+// GetUserStreak - Get a user's daily completion goal and streaks
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *streaks.StreakResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/streak
+func GetUserStreak(ctx context.Context, uid string) (*streaks.StreakResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	location, err := userLocation(ctx, uid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// days may have passed since the last completion
+	return updateStreak(ctx, uid, location)
+}
+
+// UpdateDailyGoal - Set the number of tasks a user wants to complete a day
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *streaks.UpdateGoalPayload
+//	@return *streaks.StreakResponse - the streaks for the new goal
+//	@return error
+//
+// encore:api auth method=PUT path=/users/:uid/streak/goal
+func UpdateDailyGoal(ctx context.Context, uid string, payload *streaks.UpdateGoalPayload) (*streaks.StreakResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	location, err := userLocation(ctx, uid, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := streaks.SetGoal(ctx, uid, payload.DailyGoal); err != nil {
+		return nil, err
+	}
+
+	return updateStreak(ctx, uid, location)
+}
+
+// refreshStreak - recalculates a user's streaks after tasks were completed or uncompleted
+// and publishes the milestone the current streak reached.
+// Failures are logged, completing tasks does not depend on streaks.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+func refreshStreak(ctx context.Context, uid string) {
+	location, err := userLocation(ctx, uid, "")
+	if err != nil {
+		rlog.Error("refreshing streak failed", "uid", uid, "err", err)
+		return
+	}
+
+	if _, err := updateStreak(ctx, uid, location); err != nil {
+		rlog.Error("refreshing streak failed", "uid", uid, "err", err)
+	}
+}
+
+// updateStreak - recalculates a user's streaks and publishes the milestone the current streak reached.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param location - the timezone of the user
+//	@return *streaks.StreakResponse
+//	@return error
+func updateStreak(ctx context.Context, uid string, location *time.Location) (*streaks.StreakResponse, error) {
+	streak, milestone, err := streaks.Refresh(ctx, uid, location)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish the milestone, the streak is saved even when it fails
+	if milestone > 0 {
+		_, err := events.StreakMilestone.Publish(ctx, &events.StreakMilestoneEvent{
+			UserID:    uid,
+			Milestone: milestone,
+			StartedOn: streak.StartedOn,
+		})
+		logPublish("streak-milestone", err)
+	}
+
+	return streak, nil
+}
+
+// =====================================================================================================================
+// CALDAV
+// =====================================================================================================================
+
+// CalDAV - Sync the tasks of a user with CalDAV clients (Apple Reminders, Thunderbird, DAVx5)
+// Categories are calendars and tasks are VTODOs, requests use basic (email, password) or bearer authentication.
+//
+//	@route OPTIONS|PROPFIND|REPORT|GET|HEAD|PUT|DELETE /dav/*path
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//
+// encore:api public raw method=* path=/dav/*path
+func CalDAV(w http.ResponseWriter, req *http.Request) {
+	change := caldav.Serve(w, req)
+
+	// publish the changes of CalDAV clients like those made through the api
+	switch {
+	case change == nil:
+	case change.Task == nil:
+		_, err := events.TaskDeleted.Publish(req.Context(), &events.TaskDeletedEvent{Task: change.Previous.Event()})
+		logPublish("task-deleted", err)
+	default:
+		publishTaskChange(req.Context(), change.Previous, change.Task)
+	}
+}
+
+// =====================================================================================================================
+// EVENTS
+// =====================================================================================================================
+
+// publishCompletion - publishes that a task was completed or uncompleted.
+//
+//	@param ctx - context.Context
+//	@param task - *ts.Task
+func publishCompletion(ctx context.Context, task *ts.Task) {
+	if task.Completed {
+		_, err := events.TaskCompleted.Publish(ctx, &events.TaskCompletedEvent{Task: task.Event()})
+		logPublish("task-completed", err)
+		return
+	}
+
+	_, err := events.TaskUncompleted.Publish(ctx, &events.TaskUncompletedEvent{Task: task.Event()})
+	logPublish("task-uncompleted", err)
+}
+
+// publishTaskChange - publishes the events of a task saved as a whole: created when there was no previous
+// state, otherwise updated with the changed fields, archived, and completed or uncompleted.
+//
+//	@param ctx - context.Context
+//	@param previous - the task before the change, nil when it was created
+//	@param task - the task after the change
+//	@return bool - true when the task was completed or uncompleted
+func publishTaskChange(ctx context.Context, previous, task *ts.Task) bool {
+	if previous == nil {
+		_, err := events.TaskCreated.Publish(ctx, &events.TaskCreatedEvent{Task: task.Event()})
+		logPublish("task-created", err)
+		return false
+	}
+
+	if changed := ts.Changes(previous, task); len(changed) > 0 {
+		_, err := events.TaskUpdated.Publish(ctx, &events.TaskUpdatedEvent{Task: task.Event(), Changed: changed})
+		logPublish("task-updated", err)
+	}
+	if task.Archived && !previous.Archived {
+		_, err := events.TaskArchived.Publish(ctx, &events.TaskArchivedEvent{Task: task.Event()})
+		logPublish("task-archived", err)
+	}
+	if task.Completed != previous.Completed {
+		publishCompletion(ctx, task)
+		return true
+	}
+
+	return false
+}
+
+// publishCategoryChange - publishes the events of a category saved as a whole.
+//
+//	@param ctx - context.Context
+//	@param previous - the category before the change, nil when it was created
+//	@param category - the category after the change
+func publishCategoryChange(ctx context.Context, previous, category *cs.Category) {
+	if previous == nil {
+		_, err := events.CategoryCreated.Publish(ctx, &events.CategoryCreatedEvent{Category: category.Event()})
+		logPublish("category-created", err)
+		return
+	}
+
+	if changed := cs.Changes(previous, category); len(changed) > 0 {
+		_, err := events.CategoryUpdated.Publish(ctx, &events.CategoryUpdatedEvent{Category: category.Event(), Changed: changed})
+		logPublish("category-updated", err)
+	}
+}
+
+// logPublish - logs a failed publish. The change the event describes is already saved,
+// so the request does not fail.
+//
+//	@param topic - string
+//	@param err - error
+func logPublish(topic string, err error) {
+	if err != nil {
+		rlog.Error("publishing event failed", "topic", topic, "err", err)
+	}
+}
+
+// =====================================================================================================================
+// WEBHOOKS
+// =====================================================================================================================
+
+// CreateWebhook - Register an endpoint receiving the task and category events of a user
+// The response holds the secret signing the payloads, it is not returned again.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *webhooks.CreateWebhookPayload
+//	@return *webhooks.WebhookResponse
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/webhooks
+func CreateWebhook(ctx context.Context, uid string, payload *webhooks.CreateWebhookPayload) (*webhooks.WebhookResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return webhooks.Create(ctx, uid, payload)
+}
+
+// GetUserWebhooks - Get the webhooks of a user
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *webhooks.WebhooksResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/webhooks
+func GetUserWebhooks(ctx context.Context, uid string) (*webhooks.WebhooksResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	return webhooks.List(ctx, uid)
+}
+
+// UpdateWebhook - Change the url, events or description of a webhook, or enable it again after it was disabled
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param payload - *webhooks.UpdateWebhookPayload
+//	@return *webhooks.WebhookResponse
+//	@return error
+//
+// encore:api auth method=PATCH path=/users/:uid/webhooks/:id
+func UpdateWebhook(ctx context.Context, uid, id string, payload *webhooks.UpdateWebhookPayload) (*webhooks.WebhookResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return webhooks.Update(ctx, uid, id, payload)
+}
+
+// DeleteWebhook - Delete a webhook and its delivery log
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return error
+//
+// encore:api auth method=DELETE path=/users/:uid/webhooks/:id
+func DeleteWebhook(ctx context.Context, uid, id string) error {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return err
+	}
+
+	return webhooks.Delete(ctx, uid, id)
+}
+
+// GetWebhookDeliveries - Get the delivery log of a webhook, the newest deliveries first
+//
+//	@route GET /users/:uid/webhooks/:id/deliveries?status=pending|retrying|delivered|failed&page=&limit=
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param options - *webhooks.DeliveriesOptions
+//	@return *webhooks.DeliveriesResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/webhooks/:id/deliveries
+func GetWebhookDeliveries(ctx context.Context, uid, id string, options *webhooks.DeliveriesOptions) (*webhooks.DeliveriesResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate options
+	if err := validator.New().Struct(options); err != nil {
+		return nil, err
+	}
+
+	return webhooks.Deliveries(ctx, uid, id, options)
+}
+
+// SUBSCRIPTIONS - Subscription sending the webhook deliveries, failed attempts are retried with exponential backoff
+var _ = pubsub.NewSubscription(
+	events.WebhookDelivery,
+	"deliver-webhook",
+	pubsub.SubscriptionConfig[*events.WebhookDeliveryEvent]{
+		Handler: func(ctx context.Context, event *events.WebhookDeliveryEvent) error {
+			return webhooks.Deliver(ctx, event.DeliveryID)
+		},
+		RetryPolicy: &pubsub.RetryPolicy{
+			MinBackoff: 30 * time.Second,
+			MaxBackoff: 2 * time.Hour,
+			MaxRetries: webhooks.MaxAttempts - 1,
+		},
+	},
+)
+
+// SUBSCRIPTIONS - Subscriptions delivering the task and category events to the webhooks of their owner
+var _ = pubsub.NewSubscription(
+	events.TaskCreated,
+	"webhooks-task-created",
+	pubsub.SubscriptionConfig[*events.TaskCreatedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskCreatedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskCreated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUpdated,
+	"webhooks-task-updated",
+	pubsub.SubscriptionConfig[*events.TaskUpdatedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUpdatedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskUpdated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskCompleted,
+	"webhooks-task-completed",
+	pubsub.SubscriptionConfig[*events.TaskCompletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskCompletedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskCompleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUncompleted,
+	"webhooks-task-uncompleted",
+	pubsub.SubscriptionConfig[*events.TaskUncompletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUncompletedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskUncompleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskArchived,
+	"webhooks-task-archived",
+	pubsub.SubscriptionConfig[*events.TaskArchivedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskArchivedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskArchived, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUnsnoozed,
+	"webhooks-task-unsnoozed",
+	pubsub.SubscriptionConfig[*events.TaskUnsnoozedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUnsnoozedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskUnsnoozed, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskDeleted,
+	"webhooks-task-deleted",
+	pubsub.SubscriptionConfig[*events.TaskDeletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskDeletedEvent) error {
+			return dispatchWebhooks(ctx, event.Task.UserID, webhooks.EventTaskDeleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryCreated,
+	"webhooks-category-created",
+	pubsub.SubscriptionConfig[*events.CategoryCreatedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryCreatedEvent) error {
+			return dispatchWebhooks(ctx, event.Category.UserID, webhooks.EventCategoryCreated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryUpdated,
+	"webhooks-category-updated",
+	pubsub.SubscriptionConfig[*events.CategoryUpdatedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryUpdatedEvent) error {
+			return dispatchWebhooks(ctx, event.Category.UserID, webhooks.EventCategoryUpdated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryDeleted,
+	"webhooks-category-deleted",
+	pubsub.SubscriptionConfig[*events.CategoryDeletedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryDeletedEvent) error {
+			return dispatchWebhooks(ctx, event.Category.UserID, webhooks.EventCategoryDeleted, event)
+		},
+	},
+)
+
+// dispatchWebhooks - logs and publishes the deliveries of an event to the webhooks of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param event - the webhook event name
+//	@param data - the domain event
+//	@return error
+func dispatchWebhooks(ctx context.Context, uid, event string, data any) error {
+	// the message id is the same across redeliveries, the deliveries of a message are logged once
+	var eventID string
+	if message := encore.CurrentRequest().Message; message != nil {
+		eventID = message.ID
+	}
+	if len(eventID) == 0 {
+		return fmt.Errorf("dispatching webhooks: missing message id")
+	}
+
+	ids, err := webhooks.Dispatch(ctx, uid, eventID, event, data)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := events.WebhookDelivery.Publish(ctx, &events.WebhookDeliveryEvent{DeliveryID: id}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// authorizeUser - checks that the authenticated user is the user of the path.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return error
+func authorizeUser(ctx context.Context, uid string) error {
+	claims, err := middleware.GetVerifiedClaims(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if claims.Subject.ID != uid {
+		return fmt.Errorf("unauthorized: you are not authorized to perform this action")
+	}
+
+	return nil
+}
+
+// =====================================================================================================================
+// LIVE
+// =====================================================================================================================
+
+// StreamUserEvents - Receive the task and category changes of a user as Server-Sent Events
+// The stream sends a heartbeat comment when idle. Reconnecting clients send the Last-Event-ID header
+// (or the lastEventId query parameter) to receive the events they missed, for up to a day.
+//
+//	@route GET /users/:uid/events
+//	@param w http.ResponseWriter
+//	@param req *http.Request
+//
+// encore:api auth raw method=GET path=/users/:uid/events
+func StreamUserEvents(w http.ResponseWriter, req *http.Request) {
+	uid := encore.CurrentRequest().PathParams.Get("uid")
+	if err := authorizeUser(req.Context(), uid); err != nil {
+		writeJSONErrorResponse(w, &errs.Error{Code: errs.PermissionDenied, Message: err.Error()})
+		return
+	}
+
+	if err := live.Serve(w, req, uid); err != nil {
+		writeJSONErrorResponse(w, err)
+	}
+}
+
+// SUBSCRIPTIONS - Subscriptions sending the task and category events to the open streams of their owner
+var _ = pubsub.NewSubscription(
+	events.TaskCreated,
+	"live-task-created",
+	pubsub.SubscriptionConfig[*events.TaskCreatedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskCreatedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskCreated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUpdated,
+	"live-task-updated",
+	pubsub.SubscriptionConfig[*events.TaskUpdatedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUpdatedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskUpdated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskCompleted,
+	"live-task-completed",
+	pubsub.SubscriptionConfig[*events.TaskCompletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskCompletedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskCompleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUncompleted,
+	"live-task-uncompleted",
+	pubsub.SubscriptionConfig[*events.TaskUncompletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUncompletedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskUncompleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskArchived,
+	"live-task-archived",
+	pubsub.SubscriptionConfig[*events.TaskArchivedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskArchivedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskArchived, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskUnsnoozed,
+	"live-task-unsnoozed",
+	pubsub.SubscriptionConfig[*events.TaskUnsnoozedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskUnsnoozedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskUnsnoozed, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.TaskDeleted,
+	"live-task-deleted",
+	pubsub.SubscriptionConfig[*events.TaskDeletedEvent]{
+		Handler: func(ctx context.Context, event *events.TaskDeletedEvent) error {
+			return live.Publish(ctx, event.Task.UserID, webhooks.EventTaskDeleted, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryCreated,
+	"live-category-created",
+	pubsub.SubscriptionConfig[*events.CategoryCreatedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryCreatedEvent) error {
+			return live.Publish(ctx, event.Category.UserID, webhooks.EventCategoryCreated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryUpdated,
+	"live-category-updated",
+	pubsub.SubscriptionConfig[*events.CategoryUpdatedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryUpdatedEvent) error {
+			return live.Publish(ctx, event.Category.UserID, webhooks.EventCategoryUpdated, event)
+		},
+	},
+)
+
+var _ = pubsub.NewSubscription(
+	events.CategoryDeleted,
+	"live-category-deleted",
+	pubsub.SubscriptionConfig[*events.CategoryDeletedEvent]{
+		Handler: func(ctx context.Context, event *events.CategoryDeletedEvent) error {
+			return live.Publish(ctx, event.Category.UserID, webhooks.EventCategoryDeleted, event)
+		},
+	},
+)
+
+// cron job deleting the events streams can no longer resume from
+var _ = cron.NewJob("prune-live-events", cron.JobConfig{
+	Title:    "Prune live events",
+	Every:    1 * cron.Hour,
+	Endpoint: PruneLiveEvents,
+})
+
+// PruneLiveEvents - Delete the live events older than a day
+//
+//	@param ctx - context.Context
+//	@return error
+//
+// encore:api private
+func PruneLiveEvents(ctx context.Context) error {
+	return live.Prune(ctx)
+}
+
+// =====================================================================================================================
+// SYNC
+// =====================================================================================================================
+
+// GetUserChanges - Get the tasks and categories of a user changed since a sync token, with the deleted ones
+// Clients keep the returned token for the next sync and sync again right away while hasMore is true.
+//
+//	@route GET /users/:uid/sync?token=&limit=
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param options - *delta.SyncOptions
+//	@return *delta.SyncResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/sync
+func GetUserChanges(ctx context.Context, uid string, options *delta.SyncOptions) (*delta.SyncResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate options
+	if err := validator.New().Struct(options); err != nil {
+		return nil, err
+	}
+
+	return delta.Changes(ctx, uid, options.Token, options.Limit)
+}
+
+// SyncUserChanges - Push the mutations a client made offline and get the changes since its sync token
+// The response holds the result of every mutation, conflicts return the state that won on the server.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *delta.SyncPayload
+//	@return *delta.SyncResponse
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/sync
+func SyncUserChanges(ctx context.Context, uid string, payload *delta.SyncPayload) (*delta.SyncResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// apply the mutations
+	results, err := delta.Apply(ctx, uid, payload.Mutations)
+	if err != nil {
+		return nil, err
+	}
+	completed := false
+	for i := range results {
+		completed = publishSyncResult(ctx, &results[i]) || completed
+	}
+	if completed {
+		refreshStreak(ctx, uid)
+	}
+
+	// the changes include the applied mutations
+	response, err := delta.Changes(ctx, uid, payload.Token, payload.Limit)
+	if err != nil {
+		return nil, err
+	}
+	response.Results = results
+
+	return response, nil
+}
+
+// publishSyncResult - publishes the events of an applied mutation.
+//
+//	@param ctx - context.Context
+//	@param result - *delta.Result
+//	@return bool - true when a task was completed or uncompleted
+func publishSyncResult(ctx context.Context, result *delta.Result) bool {
+	if result.Status != delta.StatusApplied {
+		return false
+	}
+
+	switch {
+	case result.Kind == delta.KindTask && result.Op == delta.OpDelete && result.PreviousTask() != nil:
+		_, err := events.TaskDeleted.Publish(ctx, &events.TaskDeletedEvent{Task: result.PreviousTask().Event()})
+		logPublish("task-deleted", err)
+	case result.Kind == delta.KindTask && result.Task != nil:
+		return publishTaskChange(ctx, result.PreviousTask(), result.Task)
+	case result.Kind == delta.KindCategory && result.Op == delta.OpDelete && result.PreviousCategory() != nil:
+		_, err := events.CategoryDeleted.Publish(ctx, &events.CategoryDeletedEvent{Category: result.PreviousCategory().Event()})
+		logPublish("category-deleted", err)
+	case result.Kind == delta.KindCategory && result.Category != nil:
+		publishCategoryChange(ctx, result.PreviousCategory(), result.Category)
+	}
+
+	return false
+}
+
+// =====================================================================================================================
+// UNDO
+// =====================================================================================================================
+
+// Undo - Restore the tasks and categories of an operation to their state before it
+// Operations return an undo token valid for a few minutes. Nothing is restored when one of the tasks
+// or categories changed after the operation.
+//
+//	@param ctx - context.Context
+//	@param token - string
+//	@return *undo.RestoreResponse
+//	@return error
+//
+// encore:api auth method=POST path=/undo/:token
+func Undo(ctx context.Context, token string) (*undo.RestoreResponse, error) {
+	uid, _ := auth.UserID()
+
+	response, err := undo.Restore(ctx, string(uid), token)
+	if err != nil {
+		return nil, err
+	}
+
+	// publish the restored state
+	refreshed := map[string]bool{}
+	response.EachTask(func(previous, task *ts.Task) {
+		if publishTaskChange(ctx, previous, task) && !refreshed[task.UserID] {
+			refreshed[task.UserID] = true
+			refreshStreak(ctx, task.UserID)
+		}
+	})
+	response.EachCategory(func(previous, category *cs.Category) {
+		publishCategoryChange(ctx, previous, category)
+	})
+
+	return response, nil
+}
+
+// recordUndo - saves the state before an operation of the authenticated user and returns its undo token.
+// Failures are logged, the operation then cannot be undone.
+//
+//	@param ctx - context.Context
+//	@param operation - string
+//	@param entries - the tasks and categories before the operation
+//	@return *undo.UndoResponse
+func recordUndo(ctx context.Context, operation string, entries ...undo.Entry) *undo.UndoResponse {
+	// operations of subscriptions have no user to undo them
+	uid, ok := auth.UserID()
+	if !ok || len(entries) < 1 {
+		return &undo.UndoResponse{}
+	}
+
+	response, err := undo.Record(ctx, string(uid), operation, entries)
+	if err != nil {
+		rlog.Error("recording undo failed", "uid", uid, "operation", operation, "err", err)
+		return &undo.UndoResponse{}
+	}
+
+	return response
+}
+
+// =====================================================================================================================
+// SNOOZE
+// =====================================================================================================================
+
+// SnoozeTask - Hide a task from the default listings until a preset or a time
+// The presets "later_today", "tomorrow" and "next_week" are resolved in the user's timezone.
+//
+//	@param ctx - context.Context
+//	@param id - string
+//	@param payload - *snooze.SnoozePayload
+//	@return *snooze.SnoozeResponse
+//	@return error
+//
+// encore:api auth method=POST path=/tasks/snooze/:id
+func SnoozeTask(ctx context.Context, id string, payload *snooze.SnoozePayload) (*snooze.SnoozeResponse, error) {
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	// get the task before the change
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUser(ctx, before.UserID); err != nil {
+		return nil, err
+	}
+
+	// resolve the preset in the timezone of the owner
+	location, err := userLocation(ctx, before.UserID, payload.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	until, err := snooze.Until(payload, time.Now().In(location))
+	if err != nil {
+		return nil, err
+	}
+
+	// snooze task
+	task, err := ts.Snooze(ctx, id, &until)
+	if err != nil {
+		return nil, err
+	}
+	publishTaskChange(ctx, before, task)
+
+	return &snooze.SnoozeResponse{Task: *task, Timezone: location.String()}, nil
+}
+
+// UnsnoozeTask - List a snoozed task again right away
+//
+//	@param ctx - context.Context
+//	@param id - string
+//	@return *ts.Task
+//	@return error
+//
+// encore:api auth method=DELETE path=/tasks/snooze/:id
+func UnsnoozeTask(ctx context.Context, id string) (*ts.Task, error) {
+	// get the task before the change
+	before, err := ts.Get(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeUser(ctx, before.UserID); err != nil {
+		return nil, err
+	}
+	if before.SnoozedUntil == nil {
+		return before, nil
+	}
+
+	// end the snooze
+	task, err := ts.Snooze(ctx, id, nil)
+	if err != nil {
+		return nil, err
+	}
+	publishTaskChange(ctx, before, task)
+
+	_, err = events.TaskUnsnoozed.Publish(ctx, &events.TaskUnsnoozedEvent{Task: task.Event()})
+	logPublish("task-unsnoozed", err)
+
+	return task, nil
+}
+
+// cron job listing the snoozed tasks again once their snooze ended
+var _ = cron.NewJob("unsnooze-tasks", cron.JobConfig{
+	Title:    "Unsnooze tasks",
+	Every:    5 * cron.Minute,
+	Endpoint: UnsnoozeTasks,
+})
+
+// UnsnoozeTasks - End the snooze of the tasks snoozed until a time that has passed
+//
+//	@param ctx - context.Context
+//	@return error
+//
+// encore:api private
+func UnsnoozeTasks(ctx context.Context) error {
+	tasks, err := ts.Unsnooze(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		_, err := events.TaskUnsnoozed.Publish(ctx, &events.TaskUnsnoozedEvent{Task: tasks[i].Event()})
+		logPublish("task-unsnoozed", err)
+	}
+
+	return nil
+}
+
+// =====================================================================================================================
+// FILTERS
+// =====================================================================================================================
+
+// CreateFilter - Save a filter as a named smart list of a user
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *filters.CreateFilterPayload
+//	@return *filters.FilterResponse
+//	@return error
+//
+// encore:api auth method=POST path=/users/:uid/filters
+func CreateFilter(ctx context.Context, uid string, payload *filters.CreateFilterPayload) (*filters.FilterResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return filters.Create(ctx, uid, payload)
+}
+
+// GetUserFilters - Get the built-in lists and the saved filters of a user
+// The built-in lists are "today", "upcoming", "overdue", "no-category" and "recently-completed".
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *filters.FiltersResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/filters
+func GetUserFilters(ctx context.Context, uid string) (*filters.FiltersResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	return filters.List(ctx, uid)
+}
+
+// GetFilter - Get a built-in list or a saved filter of a user
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - the id of a saved filter or the name of a built-in list
+//	@return *filters.FilterResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/filters/:id
+func GetFilter(ctx context.Context, uid, id string) (*filters.FilterResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	return filters.Find(ctx, uid, id)
+}
+
+// UpdateFilter - Rename a saved filter or replace its criteria
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param payload - *filters.UpdateFilterPayload
+//	@return *filters.FilterResponse
+//	@return error
+//
+// encore:api auth method=PATCH path=/users/:uid/filters/:id
+func UpdateFilter(ctx context.Context, uid, id string, payload *filters.UpdateFilterPayload) (*filters.FilterResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return filters.Update(ctx, uid, id, payload)
+}
+
+// DeleteFilter - Delete a saved filter
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return error
+//
+// encore:api auth method=DELETE path=/users/:uid/filters/:id
+func DeleteFilter(ctx context.Context, uid, id string) error {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return err
+	}
+
+	return filters.Delete(ctx, uid, id)
+}
+
+// EvaluateFilter - Get a page of the tasks matching a built-in list or a saved filter
+// Due dates are resolved in the user's timezone unless another one is given.
+//
+//	@route GET /users/:uid/filters/:id/tasks?page=&limit=&timezone=
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - the id of a saved filter or the name of a built-in list
+//	@param options - *filters.EvaluateOptions
+//	@return *filters.EvaluateResponse
+//	@return error
+//
+// encore:api auth method=GET path=/users/:uid/filters/:id/tasks
+func EvaluateFilter(ctx context.Context, uid, id string, options *filters.EvaluateOptions) (*filters.EvaluateResponse, error) {
+	if err := authorizeUser(ctx, uid); err != nil {
+		return nil, err
+	}
+
+	// validate options
+	if err := validator.New().Struct(options); err != nil {
+		return nil, err
+	}
+
+	filter, err := filters.Find(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := userLocation(ctx, uid, options.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return filters.Evaluate(ctx, uid, filter, options, location)
+}
+
+// =====================================================================================================================
+// COLORS
+// =====================================================================================================================
+
+// GetColorPalette - Get the named colors tasks and categories can use
+// Tasks and categories also accept hex ("#rgb", "#rrggbb") and rgb(r, g, b) colors, stored as "#rrggbb".
+//
+//	@param ctx - context.Context
+//	@return *color.PaletteResponse
+//	@return error
+//
+// encore:api public method=GET path=/colors
+func GetColorPalette(ctx context.Context) (*color.PaletteResponse, error) {
+	return &color.PaletteResponse{Colors: color.Palette()}, nil
+}
+
+// =====================================================================================================================
+// DEFAULT CATEGORIES
+// =====================================================================================================================
+
+// GetDefaultCategories - Get the categories new users start with, in order
+//
+//	@param ctx - context.Context
+//	@return *defaults.DefaultCategoriesResponse
+//	@return error
+//
+// encore:api auth method=GET path=/admin/default-categories
+func GetDefaultCategories(ctx context.Context) (*defaults.DefaultCategoriesResponse, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return defaults.List(ctx)
+}
+
+// SetDefaultCategories - Replace the categories new users start with
+// Users whose default categories were already created keep them.
+//
+//	@param ctx - context.Context
+//	@param payload - *defaults.SetDefaultCategoriesPayload
+//	@return *defaults.DefaultCategoriesResponse
+//	@return error
+//
+// encore:api auth method=PUT path=/admin/default-categories
+func SetDefaultCategories(ctx context.Context, payload *defaults.SetDefaultCategoriesPayload) (*defaults.DefaultCategoriesResponse, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	return defaults.Set(ctx, payload)
+}
+
+// SeedDefaultCategories - Create the default categories of a user, e.g. one who signed up before they existed
+// Only the first call for a user creates categories.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *defaults.SeedResponse
+//	@return error
+//
+// encore:api auth method=POST path=/admin/users/:uid/default-categories
+func SeedDefaultCategories(ctx context.Context, uid string) (*defaults.SeedResponse, error) {
+	if err := authorizeAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return seedDefaultCategories(ctx, uid)
+}
+
+// seedDefaultCategories - creates the default categories of a user once and publishes them.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *defaults.SeedResponse
+//	@return error
+func seedDefaultCategories(ctx context.Context, uid string) (*defaults.SeedResponse, error) {
+	response, err := defaults.Seed(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.Categories {
+		publishCategoryChange(ctx, nil, &response.Categories[i])
+	}
+
+	return response, nil
+}
+
+// SUBSCRIPTIONS - Subscription to create the default categories of new users
+// Redelivered events create nothing, the categories are created once per user.
+var _ = pubsub.NewSubscription(
+	events.UserCreated,
+	"seed-default-categories",
+	pubsub.SubscriptionConfig[*events.UserCreatedEvent]{
+		Handler: func(ctx context.Context, event *events.UserCreatedEvent) error {
+			_, err := seedDefaultCategories(ctx, event.UserID)
+			return err
+		},
+	},
+)
+
+// authorizeAdmin - checks that the authenticated user is an admin.
+//
+//	@param ctx - context.Context
+//	@return error
+func authorizeAdmin(ctx context.Context) error {
+	claims, err := middleware.GetVerifiedClaims(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if !claims.HasRole(middleware.RoleSuperAdmin, middleware.RoleAdmin) {
+		return fmt.Errorf("unauthorized: you are not authorized to perform this action")
+	}
+
+	return nil
+}
+
+// =====================================================================================================================
+// DIGEST
+// =====================================================================================================================
+
+type DigestTasksPayload struct {
+	DueFrom        time.Time `json:"dueFrom" validate:"required"`        // the start of the user's day, tasks due before are overdue
+	DueTo          time.Time `json:"dueTo" validate:"required"`          // the end of the user's day (exclusive)
+	CompletedSince time.Time `json:"completedSince" validate:"required"` // tasks completed after are listed as completed
+	Limit          int       `json:"limit" validate:"min=1,max=100"`     // the most tasks of each list
+}
+
+type DigestTasksResponse struct {
+	Overdue   []events.Task `json:"overdue"`   // the tasks due first come first
+	DueToday  []events.Task `json:"dueToday"`  // the tasks due first come first
+	Completed []events.Task `json:"completed"` // the last completed tasks first
+}
+
+// GetUserDigestTasks - Get the overdue, due today and recently completed tasks of a user's daily digest
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *DigestTasksPayload
+//	@return *DigestTasksResponse
+//	@return error
+//
+// encore:api private method=POST path=/internal/users/:uid/digest-tasks
+func GetUserDigestTasks(ctx context.Context, uid string, payload *DigestTasksPayload) (*DigestTasksResponse, error) {
+	// validate payload
+	if err := validator.New().Struct(payload); err != nil {
+		return nil, err
+	}
+
+	overdue, err := ts.GetUserOverdueTasks(ctx, uid, payload.DueFrom, payload.Limit)
+	if err != nil {
+		return nil, err
+	}
+	dueToday, err := ts.GetUserTasksDueBetween(ctx, uid, payload.DueFrom, payload.DueTo, payload.Limit)
+	if err != nil {
+		return nil, err
+	}
+	completed, err := ts.GetUserTasksCompletedSince(ctx, uid, payload.CompletedSince, payload.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DigestTasksResponse{Overdue: taskEvents(overdue), DueToday: taskEvents(dueToday), Completed: taskEvents(completed)}, nil
+}
+
+// taskEvents - returns tasks as they are shared with other services.
+//
+//	@param tasks - []ts.Task
+//	@return []events.Task
+func taskEvents(tasks []ts.Task) []events.Task {
+	shared := make([]events.Task, 0, len(tasks))
+	for i := range tasks {
+		shared = append(shared, tasks[i].Event())
+	}
+
+	return shared
 }
 
 // =====================================================================================================================
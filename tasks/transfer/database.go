@@ -0,0 +1,163 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+	"encore.app/pkg/slice"
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// get the service name
+var tasksDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// Import - Import creates the categories and tasks of an import in one transaction.
+// Rows that cannot be imported are skipped and reported, a dry run creates nothing.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *ImportPayload
+//	@return response
+//	@return error
+func Import(ctx context.Context, uid string, payload *ImportPayload) (*ImportResponse, error) {
+	// read the rows of the import
+	rows, errors, err := parse(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ImportResponse{
+		DryRun:     payload.DryRun,
+		Categories: []string{},
+		Tasks:      []ts.Task{},
+		Errors:     append([]ImportError{}, errors...),
+	}
+
+	// create everything or nothing
+	err = database.Transaction(ctx, tasksDatabase, func(tx *sqlx.Tx) error {
+		// get the user's existing categories
+		var categories []cs.Category
+		if err := database.NamedSliceQuery(ctx, tx, "SELECT * FROM categories WHERE uid = :uid", map[string]any{
+			"uid": uid,
+		}, &categories); err != nil {
+			return fmt.Errorf("selecting categories: %w", err)
+		}
+
+		// collect the names of the existing categories, new ones come last
+		var names []string
+		position := 0
+		for _, category := range categories {
+			names = append(names, category.Name)
+			if category.Position >= position {
+				position = category.Position + 1
+			}
+		}
+
+		now := time.Now().UTC()
+
+		// loop through the rows and create the tasks
+		for _, r := range rows {
+			task, err := newTask(uid, r, now)
+			if err != nil {
+				response.Errors = append(response.Errors, ImportError{Row: r.Line, Message: err.Error()})
+				continue
+			}
+
+			// create the category if it does not exist yet
+			if len(strings.TrimSpace(r.Category)) > 0 && !slice.Contains(names, task.Category) {
+				if !payload.DryRun {
+					if err := cs.Insert(ctx, tx, &cs.Category{
+						ID:        uuid.New().String(),
+						UID:       uid,
+						Name:      task.Category,
+						Position:  position,
+						CreatedAt: now,
+						UpdatedAt: now,
+					}); err != nil {
+						return err
+					}
+				}
+				names = append(names, task.Category)
+				position++
+				response.Categories = append(response.Categories, task.Category)
+			}
+
+			// create the task
+			if !payload.DryRun {
+				if err := ts.Insert(ctx, tx, task); err != nil {
+					return err
+				}
+			}
+			response.Tasks = append(response.Tasks, *task)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("importing tasks: %w", err)
+	}
+
+	// report the errors in row order
+	sort.SliceStable(response.Errors, func(i, j int) bool {
+		return response.Errors[i].Row < response.Errors[j].Row
+	})
+
+	return response, nil
+}
+
+// newTask - creates a task from an import row.
+//
+//	@param uid - string
+//	@param r - row
+//	@param now - time.Time
+//	@return *ts.Task
+//	@return error
+func newTask(uid string, r row, now time.Time) (*ts.Task, error) {
+	// a title is required
+	if len(r.Title) < 1 {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	// derive the status when it is not given
+	status := r.Status
+	switch {
+	case len(status) > 0 && !slice.Contains(statuses, status):
+		return nil, fmt.Errorf("invalid status %q", r.Status)
+	case len(status) > 0:
+	case r.Archived:
+		status = "archived"
+	case r.Completed:
+		status = "completed"
+	default:
+		status = "pending"
+	}
+
+	// tasks without a category are left without one
+	category := strings.ToLower(strings.TrimSpace(r.Category))
+
+	return &ts.Task{
+		ID:          uuid.New().String(),
+		UserID:      uid,
+		Title:       r.Title,
+		Description: r.Description,
+		Status:      status,
+		Category:    category,
+		PinnedAt:    now,
+		Archived:    r.Archived || status == "archived",
+		ArchivedAt:  now,
+		Completed:   r.Completed || status == "completed",
+		CompletedAt: now,
+		Color:       "default",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
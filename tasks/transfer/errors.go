@@ -0,0 +1,10 @@
+package transfer
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrUnsupportedFormat - the format is not one that can be imported or exported
+	ErrUnsupportedFormat = &errs.Error{Code: errs.InvalidArgument, Message: "unsupported format"}
+	// ErrInvalidData - the data could not be parsed in the given format
+	ErrInvalidData = &errs.Error{Code: errs.InvalidArgument, Message: "data could not be parsed"}
+)
@@ -0,0 +1,255 @@
+package transfer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"encore.app/pkg/pagination"
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// export formats mapped to their content types
+var contentTypes = map[string]string{
+	"csv":      "text/csv; charset=utf-8",
+	"ndjson":   "application/x-ndjson",
+	"markdown": "text/markdown; charset=utf-8",
+}
+
+// ContentType - returns the content type of an export format.
+//
+//	@param format - csv, ndjson, markdown
+//	@return string
+//	@return error
+func ContentType(format string) (string, error) {
+	contentType, ok := contentTypes[format]
+	if !ok {
+		return "", ErrUnsupportedFormat
+	}
+
+	return contentType, nil
+}
+
+// ExportTasks - streams a user's tasks to w in the given format.
+// Tasks are written as they are read from the database, so exports of any size use little memory.
+//
+//	@param ctx - context.Context
+//	@param w - io.Writer
+//	@param uid - string
+//	@param format - csv, ndjson, markdown
+//	@param options - the sort order and fields of the tasks
+//	@return error
+func ExportTasks(ctx context.Context, w io.Writer, uid, format string, options *pagination.Options) error {
+	switch format {
+	case "csv":
+		header := exportFields(ts.Task{}, options.Fields)
+		return writeCSV(w, header, func(fn func(any) error) error {
+			return ts.StreamUserTasks(ctx, uid, options, false, func(task ts.Task) error { return fn(task) })
+		})
+	case "ndjson":
+		return writeNDJSON(w, func(fn func(any) error) error {
+			return ts.StreamUserTasks(ctx, uid, options, false, func(task ts.Task) error { return fn(task) })
+		})
+	case "markdown":
+		return writeMarkdown(ctx, w, uid, options)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// ExportCategories - streams a user's categories to w in the given format.
+//
+//	@param ctx - context.Context
+//	@param w - io.Writer
+//	@param uid - string
+//	@param format - csv, ndjson
+//	@param options - the sort order and fields of the categories
+//	@return error
+func ExportCategories(ctx context.Context, w io.Writer, uid, format string, options *pagination.Options) error {
+	switch format {
+	case "csv":
+		header := exportFields(cs.Category{}, options.Fields)
+		return writeCSV(w, header, func(fn func(any) error) error {
+			return cs.StreamUserCategories(ctx, uid, options, func(category cs.Category) error { return fn(category) })
+		})
+	case "ndjson":
+		return writeNDJSON(w, func(fn func(any) error) error {
+			return cs.StreamUserCategories(ctx, uid, options, func(category cs.Category) error { return fn(category) })
+		})
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// writeCSV - writes a header and one record per streamed item.
+//
+//	@param w - io.Writer
+//	@param header - the json names of the fields to write
+//	@param stream - passes every item to the function it is given
+//	@return error
+func writeCSV(w io.Writer, header []string, stream func(func(any) error) error) error {
+	writer := csv.NewWriter(w)
+
+	// write the header
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	// write the records
+	if err := stream(func(item any) error {
+		record, err := csvRecord(item, header)
+		if err != nil {
+			return err
+		}
+		return writer.Write(record)
+	}); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeNDJSON - writes one JSON document per line for every streamed item.
+//
+//	@param w - io.Writer
+//	@param stream - passes every item to the function it is given
+//	@return error
+func writeNDJSON(w io.Writer, stream func(func(any) error) error) error {
+	writer := bufio.NewWriter(w)
+	encoder := json.NewEncoder(writer)
+
+	// write the items
+	if err := stream(func(item any) error {
+		return encoder.Encode(item)
+	}); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// writeMarkdown - writes the tasks as a checklist with a heading per category.
+//
+//	@param ctx - context.Context
+//	@param w - io.Writer
+//	@param uid - string
+//	@param options - the sort order of the tasks within a category
+//	@return error
+func writeMarkdown(ctx context.Context, w io.Writer, uid string, options *pagination.Options) error {
+	// collect the category descriptions, a user has few categories
+	descriptions := map[string]string{}
+	if err := cs.StreamUserCategories(ctx, uid, &pagination.Options{}, func(category cs.Category) error {
+		descriptions[category.Name] = category.Description
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(w)
+	category := ""
+	first := true
+
+	// the checklist needs whole tasks
+	tasksOptions := *options
+	tasksOptions.Fields = ""
+
+	// write the tasks grouped by category
+	if err := ts.StreamUserTasks(ctx, uid, &tasksOptions, true, func(task ts.Task) error {
+		// write a heading when the category changes
+		if first || task.Category != category {
+			if !first {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "## %v\n\n", task.Category)
+			if description := strings.TrimSpace(descriptions[task.Category]); len(description) > 0 {
+				fmt.Fprintf(writer, "> %v\n\n", strings.ReplaceAll(description, "\n", "\n> "))
+			}
+			category, first = task.Category, false
+		}
+
+		// write the checklist item
+		check := " "
+		if task.Completed {
+			check = "x"
+		}
+		fmt.Fprintf(writer, "- [%v] %v\n", check, strings.ReplaceAll(task.Title, "\n", " "))
+
+		// indent the description under the item
+		if description := strings.TrimSpace(task.Description); len(description) > 0 {
+			fmt.Fprintf(writer, "  %v\n", strings.ReplaceAll(description, "\n", "\n  "))
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// exportFields - returns the requested fields or the json names of all fields of v.
+//
+//	@param v - the struct to read the tags from
+//	@param fields - comma separated json names, all when empty
+//	@return []string
+func exportFields(v any, fields string) []string {
+	header := []string{}
+
+	// use the requested fields
+	if len(strings.TrimSpace(fields)) > 0 {
+		for _, field := range strings.Split(fields, ",") {
+			header = append(header, strings.TrimSpace(field))
+		}
+		return header
+	}
+
+	// loop through the exported fields in order
+	vt := reflect.TypeOf(v)
+	for i := 0; i < vt.NumField(); i++ {
+		name := strings.Split(vt.Field(i).Tag.Get("json"), ",")[0]
+		if vt.Field(i).IsExported() && len(name) > 0 && name != "-" {
+			header = append(header, name)
+		}
+	}
+
+	return header
+}
+
+// csvRecord - returns the values of the header fields of an item.
+//
+//	@param item - any
+//	@param header - []string
+//	@return []string
+//	@return error
+func csvRecord(item any, header []string) ([]string, error) {
+	// read the item through its json form so the field names match the api
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+
+	record := []string{}
+	for _, field := range header {
+		switch v := values[field].(type) {
+		case nil:
+			record = append(record, "")
+		case string:
+			record = append(record, v)
+		default:
+			record = append(record, fmt.Sprint(v))
+		}
+	}
+
+	return record, nil
+}
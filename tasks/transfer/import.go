@@ -0,0 +1,212 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"encore.app/pkg/slice"
+)
+
+// importFields - the task fields a csv column can be mapped to
+var importFields = []string{"title", "description", "category", "status", "completed"}
+
+// statuses - the valid task statuses
+var statuses = []string{"pending", "completed", "archived"}
+
+// parse - reads the rows of an import in the given format.
+//
+//	@param payload - *ImportPayload
+//	@return []row
+//	@return []ImportError
+//	@return error
+func parse(payload *ImportPayload) ([]row, []ImportError, error) {
+	switch payload.Format {
+	case "csv":
+		return parseCSV(payload.Data, payload.Mapping)
+	case "todoist":
+		return parseTodoist(payload.Data)
+	case "trello":
+		return parseTrello(payload.Data)
+	default:
+		return nil, nil, ErrUnsupportedFormat
+	}
+}
+
+// parseCSV - reads rows from csv data with a header line.
+// Columns are matched to task fields by the mapping, or by name when a field is not mapped.
+//
+//	@param data - string
+//	@param mapping - task field -> csv column
+//	@return []row
+//	@return []ImportError
+//	@return error
+func parseCSV(data string, mapping map[string]string) ([]row, []ImportError, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// read the header
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, ErrInvalidData
+	}
+
+	// check the mapping
+	for field := range mapping {
+		if !slice.Contains(importFields, field) {
+			return nil, nil, fmt.Errorf("%w: unknown field %q in mapping", ErrInvalidData, field)
+		}
+	}
+
+	// find the column index of every field
+	columns := map[string]int{}
+	for _, field := range importFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				columns[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, nil, fmt.Errorf("%w: no column for title", ErrInvalidData)
+	}
+
+	// value returns the value of a field in a record
+	value := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []row
+	var errors []ImportError
+
+	// read the records, the header is line 1
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			errors = append(errors, ImportError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		r := row{
+			Line:        line,
+			Title:       value(record, "title"),
+			Description: value(record, "description"),
+			Category:    value(record, "category"),
+			Status:      strings.ToLower(value(record, "status")),
+		}
+
+		// parse the completed flag
+		if completed := value(record, "completed"); len(completed) > 0 {
+			c, ok := parseBool(completed)
+			if !ok {
+				errors = append(errors, ImportError{Row: line, Message: fmt.Sprintf("invalid completed value %q", completed)})
+				continue
+			}
+			r.Completed = c
+		}
+
+		rows = append(rows, r)
+	}
+
+	return rows, errors, nil
+}
+
+// parseTodoist - reads rows from a Todoist JSON export, projects become categories.
+//
+//	@param data - string
+//	@return []row
+//	@return []ImportError
+//	@return error
+func parseTodoist(data string) ([]row, []ImportError, error) {
+	var export todoistExport
+
+	// decode numbers as json.Number so ids keep their digits
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&export); err != nil {
+		return nil, nil, ErrInvalidData
+	}
+
+	// map the project ids to their names
+	projects := map[string]string{}
+	for _, project := range export.Projects {
+		projects[fmt.Sprint(project.ID)] = project.Name
+	}
+
+	var rows []row
+	for i, item := range export.Items {
+		rows = append(rows, row{
+			Line:        i + 1,
+			Title:       strings.TrimSpace(item.Content),
+			Description: item.Description,
+			Category:    projects[fmt.Sprint(item.ProjectID)],
+			Completed:   item.Checked || item.IsCompleted,
+		})
+	}
+
+	return rows, nil, nil
+}
+
+// parseTrello - reads rows from a Trello board JSON export, lists become categories.
+//
+//	@param data - string
+//	@return []row
+//	@return []ImportError
+//	@return error
+func parseTrello(data string) ([]row, []ImportError, error) {
+	var export trelloExport
+	if err := json.NewDecoder(strings.NewReader(data)).Decode(&export); err != nil {
+		return nil, nil, ErrInvalidData
+	}
+
+	// map the list ids to their names
+	lists := map[string]string{}
+	for _, list := range export.Lists {
+		lists[list.ID] = list.Name
+	}
+
+	var rows []row
+	for i, card := range export.Cards {
+		rows = append(rows, row{
+			Line:        i + 1,
+			Title:       strings.TrimSpace(card.Name),
+			Description: card.Desc,
+			Category:    lists[card.IDList],
+			Completed:   card.DueComplete,
+			Archived:    card.Closed,
+		})
+	}
+
+	return rows, nil, nil
+}
+
+// parseBool - parses the common spellings of a boolean.
+//
+//	@param s - string
+//	@return bool - the value
+//	@return bool - false if s is not a boolean
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "t", "yes", "y", "1", "x", "done", "completed":
+		return true, true
+	case "false", "f", "no", "n", "0", "":
+		return false, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,63 @@
+package transfer
+
+import "encore.app/tasks/ts"
+
+type ImportPayload struct {
+	Format  string            `json:"format" validate:"required,oneof=csv todoist trello"` // csv, todoist, trello
+	Data    string            `json:"data" validate:"required"`                            // the contents of the exported file
+	Mapping map[string]string `json:"mapping" validate:"omitempty"`                        // csv only: task field -> csv column
+	DryRun  bool              `json:"dryRun" validate:"omitempty"`                         // preview without creating anything
+}
+
+type ImportResponse struct {
+	DryRun     bool          `json:"dryRun"`
+	Categories []string      `json:"categories"` // categories that are (or would be) created
+	Tasks      []ts.Task     `json:"tasks"`      // tasks that are (or would be) created
+	Errors     []ImportError `json:"errors"`     // rows that were skipped
+}
+
+type ImportError struct {
+	Row     int    `json:"row"` // the row (csv line, task or card index) starting at 1
+	Message string `json:"message"`
+}
+
+// row - a task read from an import before it is resolved against the database
+type row struct {
+	Line        int
+	Title       string
+	Description string
+	Category    string
+	Status      string
+	Completed   bool
+	Archived    bool
+}
+
+// todoistExport - the parts of a Todoist JSON export that are imported
+type todoistExport struct {
+	Projects []struct {
+		ID   any    `json:"id"`
+		Name string `json:"name"`
+	} `json:"projects"`
+	Items []struct {
+		Content     string `json:"content"`
+		Description string `json:"description"`
+		ProjectID   any    `json:"project_id"`
+		Checked     bool   `json:"checked"`
+		IsCompleted bool   `json:"is_completed"`
+	} `json:"items"`
+}
+
+// trelloExport - the parts of a Trello board JSON export that are imported
+type trelloExport struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Cards []struct {
+		Name        string `json:"name"`
+		Desc        string `json:"desc"`
+		IDList      string `json:"idList"`
+		Closed      bool   `json:"closed"`
+		DueComplete bool   `json:"dueComplete"`
+	} `json:"cards"`
+}
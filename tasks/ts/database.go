@@ -11,7 +11,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 
+	"encore.app/pkg/color"
 	"encore.app/pkg/database"
+	"encore.app/pkg/markdown"
 	"encore.app/pkg/pagination"
 )
 
@@ -72,12 +74,12 @@ func FindManyByField(ctx context.Context, field, ops string, value any) ([]Task,
 	// declare tasks
 	var tasks []Task
 	// execute query
-	if err := database.NamedStructQuery(ctx, tasksDatabase, q, data, &tasks); err != nil {
-		if err == database.ErrNotFound {
-			return []Task{}, ErrNotFound
-		}
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, q, data, &tasks); err != nil {
 		return []Task{}, fmt.Errorf("selecting tasks by ID[%v]: %w", value, err)
 	}
+	if len(tasks) < 1 {
+		return []Task{}, ErrNotFound
+	}
 
 	return tasks, nil
 }
@@ -88,7 +90,7 @@ func FindManyByField(ctx context.Context, field, ops string, value any) ([]Task,
 // @param payload
 // @return task
 // @return error
-func Create(ctx context.Context, id string, payload *CreateTaskPayload) error {
+func Create(ctx context.Context, id string, payload *CreateTaskPayload) (*Task, error) {
 	// declare task
 	task := Task{}
 
@@ -100,25 +102,145 @@ func Create(ctx context.Context, id string, payload *CreateTaskPayload) error {
 	task.Status = "pending"
 	task.Pinned = false
 	task.Archived = false
-	task.Color = "default"
+	task.Color = color.Default
+	task.Priority = PriorityNone
+	task.Recurrence = strings.TrimSpace(payload.Recurrence)
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
 
+	// set the category, priority and color if given
+	categoryID, err := resolveCategory(ctx, id, payload.CategoryID, payload.Category)
+	if err != nil {
+		return nil, err
+	}
+	task.CategoryID = categoryID
+	if len(strings.TrimSpace(payload.Priority)) > 0 {
+		task.Priority = payload.Priority
+	}
+	if len(strings.TrimSpace(payload.Color)) > 0 {
+		c, err := color.Normalize(payload.Color)
+		if err != nil {
+			return nil, err
+		}
+		task.Color = c
+	}
+
+	// set the due date if given
+	if len(strings.TrimSpace(payload.DueAt)) > 0 {
+		dueAt, err := time.Parse(time.RFC3339, payload.DueAt)
+		if err != nil {
+			return nil, fmt.Errorf("parsing due date: %w", err)
+		}
+		dueAt = dueAt.UTC()
+		task.DueAt = &dueAt
+	}
+
 	// query statement to be executed
 	q := `
-    INSERT INTO tasks (id, uid, title, description, status, pinned, archived, color, created_at, updated_at) 
-    VALUES (:id, :uid, :title, :description, :status, :pinned, :archived, :color, :created_at, :updated_at) 
+    INSERT INTO tasks (id, uid, title, description, status, category_id, pinned, archived, color, due_at, priority, recurrence, created_at, updated_at) 
+    VALUES (:id, :uid, :title, :description, :status, :category_id, :pinned, :archived, :color, :due_at, :priority, :recurrence, :created_at, :updated_at) 
     RETURNING *
   `
 
 	// execute query
 	if err := database.NamedExecQuery(ctx, tasksDatabase, q, task); err != nil {
-		return fmt.Errorf("inserting task: %w", err)
+		return nil, fmt.Errorf("inserting task: %w", err)
 	}
 
 	// check if task was created
-	if tsk, err := FindOneByField(ctx, "id", "=", task.ID); err != nil || reflect.DeepEqual(tsk, Task{}) {
-		return fmt.Errorf("selecting task: %w", err)
+	tsk, err := FindOneByField(ctx, "id", "=", task.ID)
+	if err != nil || reflect.DeepEqual(tsk, Task{}) {
+		return nil, fmt.Errorf("selecting task: %w", err)
+	}
+
+	return &tsk, nil
+}
+
+// resolveCategory - returns the id of the user's category with the id, or else with the name.
+// Tasks without a category, or in the legacy "general" category when the user has none, get nil.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param id - string
+// @param name - string
+// @return *string
+// @return error
+func resolveCategory(ctx context.Context, uid, id, name string) (*string, error) {
+	id = strings.TrimSpace(id)
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(id) < 1 && len(name) < 1 {
+		return nil, nil
+	}
+
+	// query statement to be executed
+	q := "SELECT id FROM categories WHERE id = CAST(:id AS UUID) AND uid = :uid"
+	if len(id) < 1 {
+		q = "SELECT id FROM categories WHERE uid = :uid AND name = :name ORDER BY created_at ASC, id ASC LIMIT 1"
+	}
+
+	var category struct {
+		ID string `db:"id"`
+	}
+	// execute query
+	if err := database.NamedStructQuery(ctx, tasksDatabase, q, map[string]any{
+		"id":   id,
+		"uid":  uid,
+		"name": name,
+	}, &category); err != nil {
+		if err != database.ErrNotFound {
+			return nil, fmt.Errorf("selecting category: %w", err)
+		}
+		if len(id) < 1 && name == legacyCategory {
+			return nil, nil
+		}
+		return nil, ErrCategoryNotFound
+	}
+
+	return &category.ID, nil
+}
+
+// categoryIDValue - the category id of a task row: its :category_id when the category is the user's,
+// or else the oldest category of the user named :category
+const categoryIDValue = `COALESCE(
+      (SELECT id FROM categories WHERE id = CAST(:category_id AS UUID) AND uid = :uid),
+      (SELECT id FROM categories WHERE uid = :uid AND name = :category ORDER BY created_at ASC, id ASC LIMIT 1)
+    )`
+
+// Insert - Insert is a function that inserts a complete task row.
+// It accepts a transaction so tasks can be created together with other rows.
+// The category is resolved from the category id, or else the category name, and set on the task.
+//
+// @param ctx - context.Context
+// @param db - database connection or transaction
+// @param task - *Task
+// @return error
+func Insert(ctx context.Context, db sqlx.ExtContext, task *Task) error {
+	// tasks without a priority have none
+	if len(task.Priority) < 1 {
+		task.Priority = PriorityNone
+	}
+	if len(task.Color) < 1 {
+		task.Color = color.Default
+	}
+
+	// query statement to be executed
+	q := `
+    INSERT INTO tasks (
+      id, uid, title, description, status, category_id, pinned, pinned_at, pinned_position,
+      archived, archived_at, completed, completed_at, color, due_at, priority, recurrence, snoozed_until,
+      created_at, updated_at
+    )
+    VALUES (
+      :id, :uid, :title, :description, :status, ` + categoryIDValue + `, :pinned, :pinned_at, :pinned_position,
+      :archived, :archived_at, :completed, :completed_at, :color, :due_at, :priority, :recurrence, :snoozed_until,
+      :created_at, :updated_at
+    )
+    RETURNING category_id, category
+  `
+
+	// execute query
+	if err := database.NamedStructQuery(ctx, db, q, task, task); err != nil {
+		return fmt.Errorf("inserting task: %w", err)
 	}
 
 	return nil
@@ -128,19 +250,91 @@ func Create(ctx context.Context, id string, payload *CreateTaskPayload) error {
 //
 // @param ctx - context.Context
 // @param id - string
+// @param fields - comma separated fields to return, all when empty
 // @return task
 // @return error
-func Get(ctx context.Context, id string) (*Task, error) {
-	// check if task exists
-	task, err := FindOneByField(ctx, "id", "=", id)
+func Get(ctx context.Context, id, fields string) (*Task, error) {
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(fields, selectableColumns)
 	if err != nil {
+		return nil, err
+	}
+
+	// query statement to be executed
+	q := fmt.Sprintf("SELECT %v FROM tasks WHERE id = :id LIMIT 1", fieldset.Select("id"))
+
+	// declare task
+	var task Task
+	// execute query
+	if err := database.NamedStructQuery(ctx, tasksDatabase, q, map[string]any{"id": id}, &task); err != nil {
+		if err == database.ErrNotFound {
+			return nil, ErrNotFound
+		}
 		return nil, fmt.Errorf("selecting task: %w", err)
 	}
 
+	// only marshal the requested fields
+	task.fieldset = fieldset
+
+	// render the description when all fields are returned
+	if fieldset == nil {
+		if task.HTML, err = markdown.Render(task.Description); err != nil {
+			return nil, fmt.Errorf("rendering description: %w", err)
+		}
+	}
+
 	// return task
 	return &task, nil
 }
 
+// ToggleChecklistItem - ToggleChecklistItem is a function that checks or unchecks a task list item ("- [ ] item")
+// of a task's description.
+//
+// @param ctx - context.Context
+// @param id - string
+// @param index - the position of the item in the description, from 0
+// @return task
+// @return error
+func ToggleChecklistItem(ctx context.Context, id string, index int) (*Task, error) {
+	// lock the task so concurrent toggles do not overwrite each other
+	err := database.Transaction(ctx, tasksDatabase, func(tx *sqlx.Tx) error {
+		var task Task
+		if err := database.NamedStructQuery(ctx, tx, "SELECT * FROM tasks WHERE id = :id FOR UPDATE", map[string]any{
+			"id": id,
+		}, &task); err != nil {
+			if err == database.ErrNotFound {
+				return ErrNotFound
+			}
+			return fmt.Errorf("selecting task: %w", err)
+		}
+
+		// rewrite the description
+		description, _, err := markdown.ToggleTask(task.Description, index)
+		if err != nil {
+			return err
+		}
+
+		// query statement to be executed
+		q := "UPDATE tasks SET description = :description, updated_at = :updated_at WHERE id = :id"
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, q, map[string]any{
+			"id":          task.ID,
+			"description": description,
+			"updated_at":  time.Now().UTC(),
+		}); err != nil {
+			return fmt.Errorf("updating task: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return Get(ctx, id, "")
+}
+
 // GetMany - GetMany is a function that gets many tasks.
 //
 // @param ctx - context.Context
@@ -148,10 +342,13 @@ func Get(ctx context.Context, id string) (*Task, error) {
 // @return tasks
 // @return error
 func GetMany(ctx context.Context, ids []string) ([]Task, error) {
-	// check if task exists
-	tasks, err := FindManyByField(ctx, "id", "=", ids)
-	if err != nil {
-		return nil, fmt.Errorf("selecting task: %w", err)
+	// query statement to be executed
+	q := "SELECT * FROM tasks WHERE id = ANY(:ids)"
+
+	// execute query
+	tasks := []Task{}
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, q, map[string]any{"ids": ids}, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting tasks: %w", err)
 	}
 
 	// return task
@@ -238,16 +435,45 @@ func Update(ctx context.Context, id string, payload *UpdateTaskPayload) error {
 		return fmt.Errorf("selecting task: %w", err)
 	}
 
+	// validate and normalize the color
+	if len(strings.TrimSpace(payload.Color)) > 0 {
+		if payload.Color, err = color.Normalize(payload.Color); err != nil {
+			return err
+		}
+	}
+
 	// map for query fields
 	fields := map[string]any{}
 
+	// move the task to the category with the id or name if given
+	if len(strings.TrimSpace(payload.CategoryID)) > 0 || len(strings.TrimSpace(payload.Category)) > 0 {
+		if fields["category_id"], err = resolveCategory(ctx, task.UserID, payload.CategoryID, payload.Category); err != nil {
+			return err
+		}
+	}
+
+	// set the due date in UTC like Create, or remove it when it is empty
+	if payload.DueAt != nil {
+		fields["due_at"] = nil
+		if len(strings.TrimSpace(*payload.DueAt)) > 0 {
+			dueAt, err := time.Parse(time.RFC3339, *payload.DueAt)
+			if err != nil {
+				return fmt.Errorf("parsing due date: %w", err)
+			}
+			fields["due_at"] = dueAt.UTC()
+		}
+	}
+
 	// if not empty, update task field
-	vp := reflect.ValueOf(payload)
+	vp := reflect.ValueOf(payload).Elem()
 
 	// loop through payload fields and check for empty values
 	for i := 0; i < vp.NumField(); i++ {
-		// get the db tag name of the field
+		// get the db tag name of the field, the category and due date are set above
 		field := vp.Type().Field(i).Tag.Get("db")
+		if field == "-" {
+			continue
+		}
 		// get the value of the field
 		value := vp.Field(i).Interface()
 
@@ -280,30 +506,58 @@ func Update(ctx context.Context, id string, payload *UpdateTaskPayload) error {
 	return nil
 }
 
+// listedCondition - the condition leaving out the tasks snoozed after :now unless :include_snoozed is set
+const listedCondition = "(:include_snoozed OR snoozed_until IS NULL OR snoozed_until <= :now)"
+
 // GetUserTasks - GetUserTasks is a function that gets a user's tasks.
+// When options carry a cursor, keyset pagination is used instead of page numbers.
+// Tasks snoozed until later are left out unless includeSnoozed is set.
 //
 // @param ctx - context.Context
 // @param uid - string
+// @param options - *pagination.Options
+// @param includeSnoozed - bool
 // @return tasks
 // @return error
-func GetUserTasks(ctx context.Context, uid string, options *pagination.Options) (*PaginatedTasksResponse, error) {
+func GetUserTasks(ctx context.Context, uid string, options *pagination.Options, includeSnoozed bool) (*PaginatedTasksResponse, error) {
+	// validate the sort order
+	sorting, err := options.Sorting(sortableColumns, "DESC", "createdAt")
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(options.Fields, selectableColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	// use keyset pagination when a cursor is provided
+	if options.UsesCursor() {
+		return getUserTasksAfterCursor(ctx, uid, options, includeSnoozed, sorting, fieldset)
+	}
+
 	// declare tasks
 	var tasks []Task = []Task{}
 
 	// query statement to be executed
-	countQuery := "SELECT COUNT(*) FROM tasks WHERE uid = :uid"
+	countQuery := "SELECT COUNT(*) FROM tasks WHERE uid = :uid AND " + listedCondition
 
 	// execute query
-	count, err := database.NamedCountQuery(ctx, tasksDatabase, countQuery, map[string]any{"uid": uid})
+	count, err := database.NamedCountQuery(ctx, tasksDatabase, countQuery, map[string]any{
+		"uid":             uid,
+		"include_snoozed": includeSnoozed,
+		"now":             time.Now().UTC(),
+	})
 
 	// check for errors
 	if err != nil {
 		return nil, fmt.Errorf("counting tasks: %w", err)
 	}
 
-	// set limit to 20 if it is less than 0 or greater than count
-	if options.Limit < 1 || options.Limit > count {
-		options.Limit = 20
+	// set limit to 20 if it is less than 0, greater than count or greater than the maximum
+	if options.Limit < 1 || options.Limit > count || options.Limit > pagination.MaxLimit {
+		options.Limit = pagination.DefaultLimit
 	}
 
 	// calculate for pagination
@@ -317,21 +571,25 @@ func GetUserTasks(ctx context.Context, uid string, options *pagination.Options)
 	}
 
 	// query statement to be executed
-	query := `
-    SELECT * FROM tasks
-    WHERE uid = :uid
-    ORDER BY created_at
-    DESC LIMIT :limit OFFSET :offset
-  `
+	query := fmt.Sprintf(`
+    SELECT %v FROM tasks
+    WHERE uid = :uid AND %v
+    ORDER BY %v
+    LIMIT :limit OFFSET :offset
+  `, fieldset.Select(sorting.Names()...), listedCondition, sorting.OrderBy())
 
 	p := struct {
-		UID    string `db:"uid" json:"uid" validate:"required" url:"uid"`
-		Limit  int    `db:"limit" json:"limit" validate:"omitempty" url:"limit"`
-		Offset int    `db:"offset" json:"offset" validate:"omitempty" url:"offset"`
+		UID            string    `db:"uid" json:"uid" validate:"required" url:"uid"`
+		IncludeSnoozed bool      `db:"include_snoozed" json:"includeSnoozed" validate:"omitempty" url:"includeSnoozed"`
+		Now            time.Time `db:"now" json:"now" validate:"omitempty" url:"now"`
+		Limit          int       `db:"limit" json:"limit" validate:"omitempty" url:"limit"`
+		Offset         int       `db:"offset" json:"offset" validate:"omitempty" url:"offset"`
 	}{
-		UID:    uid,
-		Limit:  paging.PerPage(),
-		Offset: paging.Offset(),
+		UID:            uid,
+		IncludeSnoozed: includeSnoozed,
+		Now:            time.Now().UTC(),
+		Limit:          paging.PerPage(),
+		Offset:         paging.Offset(),
 	}
 
 	// execute query
@@ -339,26 +597,364 @@ func GetUserTasks(ctx context.Context, uid string, options *pagination.Options)
 		return nil, fmt.Errorf("selecting tasks: %w", err)
 	}
 
+	// create a cursor from the last task so clients can switch to keyset pagination
+	nextCursor, err := pagination.NextCursor(sorting, tasks, paging.HasNext())
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	// only marshal the requested fields
+	for i := range tasks {
+		tasks[i].fieldset = fieldset
+	}
+
 	return &PaginatedTasksResponse{
 		TotalPages:  paging.Pages(),
 		Total:       paging.Total(),
 		CurrentPage: paging.Page(),
+		NextCursor:  nextCursor,
 		Tasks:       tasks,
 	}, nil
 }
 
+// getUserTasksAfterCursor - gets the page of a user's tasks that follows the cursor.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param options - *pagination.Options
+// @param includeSnoozed - bool
+// @param sorting - *pagination.Sorting
+// @param fieldset - *pagination.Fieldset
+// @return tasks
+// @return error
+func getUserTasksAfterCursor(ctx context.Context, uid string, options *pagination.Options, includeSnoozed bool, sorting *pagination.Sorting, fieldset *pagination.Fieldset) (*PaginatedTasksResponse, error) {
+	// declare tasks
+	var tasks []Task = []Task{}
+
+	// decode and verify the cursor
+	cursor, err := pagination.DecodeCursor(options.Cursor, sorting.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	// condition selecting the tasks after the cursor
+	after, args, err := sorting.After(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// set limit to the default if it is out of range
+	if options.Limit < 1 || options.Limit > pagination.MaxLimit {
+		options.Limit = pagination.DefaultLimit
+	}
+
+	// query statement to be executed (one extra row tells if there is a next page)
+	query := fmt.Sprintf(`
+    SELECT %v FROM tasks
+    WHERE uid = :uid AND %v AND %v
+    ORDER BY %v
+    LIMIT :limit
+  `, fieldset.Select(sorting.Names()...), listedCondition, after, sorting.OrderBy())
+
+	args["uid"] = uid
+	args["include_snoozed"] = includeSnoozed
+	args["now"] = time.Now().UTC()
+	args["limit"] = options.Limit + 1
+
+	// execute query
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, query, args, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting tasks: %w", err)
+	}
+
+	// drop the extra row
+	hasNext := len(tasks) > options.Limit
+	if hasNext {
+		tasks = tasks[:options.Limit]
+	}
+
+	// create the cursor for the next page
+	nextCursor, err := pagination.NextCursor(sorting, tasks, hasNext)
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	// only marshal the requested fields
+	for i := range tasks {
+		tasks[i].fieldset = fieldset
+	}
+
+	return &PaginatedTasksResponse{
+		NextCursor: nextCursor,
+		Tasks:      tasks,
+	}, nil
+}
+
+// StreamUserTasks - StreamUserTasks is a function that passes all of a user's tasks to fn one at a time.
+// It honours the sort order and fields of the options, pagination is ignored.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param options - *pagination.Options
+// @param groupByCategory - sort by category before the requested sort order
+// @param fn - func(Task) error
+// @return error
+func StreamUserTasks(ctx context.Context, uid string, options *pagination.Options, groupByCategory bool, fn func(Task) error) error {
+	// validate the sort order
+	sorting, err := options.Sorting(sortableColumns, "DESC", "createdAt")
+	if err != nil {
+		return err
+	}
+
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(options.Fields, selectableColumns)
+	if err != nil {
+		return err
+	}
+
+	// group the tasks by category
+	required := sorting.Names()
+	orderBy := sorting.OrderBy()
+	if groupByCategory {
+		required = append(required, "category")
+		orderBy = "category ASC, " + orderBy
+	}
+
+	// query statement to be executed
+	query := fmt.Sprintf(`
+    SELECT %v FROM tasks
+    WHERE uid = :uid
+    ORDER BY %v
+  `, fieldset.Select(required...), orderBy)
+
+	// execute query
+	return database.NamedStreamQuery(ctx, tasksDatabase, query, map[string]any{"uid": uid}, func(task Task) error {
+		// only marshal the requested fields
+		task.fieldset = fieldset
+		return fn(task)
+	})
+}
+
+// StreamUserDueTasks - StreamUserDueTasks is a function that passes a user's tasks with a due date to fn one at a time.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param categories - only tasks in these categories, all when empty
+// @param fn - func(Task) error
+// @return error
+func StreamUserDueTasks(ctx context.Context, uid string, categories []string, fn func(Task) error) error {
+	// query statement to be executed
+	query := `
+    SELECT * FROM tasks
+    WHERE uid = :uid AND due_at IS NOT NULL AND (CARDINALITY(CAST(:categories AS TEXT[])) = 0 OR category = ANY(CAST(:categories AS TEXT[])))
+    ORDER BY due_at ASC, id ASC
+  `
+
+	// lower case the categories like they are stored
+	names := []string{}
+	for _, category := range categories {
+		names = append(names, strings.ToLower(strings.TrimSpace(category)))
+	}
+
+	// execute query
+	return database.NamedStreamQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":        uid,
+		"categories": names,
+	}, fn)
+}
+
+// StreamUserTasksInCategory - StreamUserTasksInCategory is a function that passes a user's tasks in a category to fn one at a time.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param categoryID - the id of the category, nil for the tasks without a category
+// @param fn - func(Task) error
+// @return error
+func StreamUserTasksInCategory(ctx context.Context, uid string, categoryID *string, fn func(Task) error) error {
+	// query statement to be executed
+	query := `
+    SELECT * FROM tasks
+    WHERE uid = :uid AND category_id IS NOT DISTINCT FROM CAST(:category_id AS UUID)
+    ORDER BY created_at ASC, id ASC
+  `
+
+	// execute query
+	return database.NamedStreamQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":         uid,
+		"category_id": categoryID,
+	}, fn)
+}
+
+// CategoryVersion - CategoryVersion is a function that returns a value which changes whenever a task in the category changes.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param categoryID - the id of the category, nil for the tasks without a category
+// @return string
+// @return error
+func CategoryVersion(ctx context.Context, uid string, categoryID *string) (string, error) {
+	// query statement to be executed
+	query := `
+    SELECT COUNT(*) AS count, COALESCE(MAX(updated_at), TIMESTAMP 'epoch') AS updated_at FROM tasks
+    WHERE uid = :uid AND category_id IS NOT DISTINCT FROM CAST(:category_id AS UUID)
+  `
+
+	var version struct {
+		Count     int       `db:"count"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+
+	// execute query
+	if err := database.NamedStructQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":         uid,
+		"category_id": categoryID,
+	}, &version); err != nil {
+		return "", fmt.Errorf("selecting category version: %w", err)
+	}
+
+	return fmt.Sprintf("%v-%v", version.Count, version.UpdatedAt.UnixMicro()), nil
+}
+
+// Replace - Replace is a function that overwrites all editable columns of a task.
+// It accepts a transaction so tasks can be replaced together with other rows.
+// The category is resolved like in Insert.
+//
+// @param ctx - context.Context
+// @param db - database connection or transaction
+// @param task - *Task
+// @return error
+func Replace(ctx context.Context, db sqlx.ExtContext, task *Task) error {
+	// query statement to be executed
+	q := `
+    UPDATE tasks SET
+      title = :title, description = :description, status = :status, category_id = ` + categoryIDValue + `,
+      pinned = :pinned, pinned_at = :pinned_at, pinned_position = :pinned_position,
+      archived = :archived, archived_at = :archived_at, completed = :completed, completed_at = :completed_at,
+      color = :color, due_at = :due_at, priority = :priority, recurrence = :recurrence,
+      snoozed_until = :snoozed_until, updated_at = :updated_at
+    WHERE id = :id AND uid = :uid
+    RETURNING category_id, category
+  `
+
+	// execute query
+	if err := database.NamedStructQuery(ctx, db, q, task, task); err != nil {
+		if err == database.ErrNotFound {
+			return nil
+		}
+		return fmt.Errorf("replacing task: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserOverdueTasks - GetUserOverdueTasks is a function that gets a user's open tasks due before a time.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param before - time.Time
+// @param limit - the maximum number of tasks
+// @return tasks - the tasks due first come first
+// @return error
+func GetUserOverdueTasks(ctx context.Context, uid string, before time.Time, limit int) ([]Task, error) {
+	// query statement to be executed
+	query := `
+    SELECT * FROM tasks
+    WHERE uid = :uid AND NOT completed AND NOT archived AND due_at < :before
+    ORDER BY due_at ASC, id ASC
+    LIMIT :limit
+  `
+
+	// execute query
+	tasks := []Task{}
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":    uid,
+		"before": before.UTC(),
+		"limit":  limit,
+	}, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting overdue tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetUserTasksDueBetween - GetUserTasksDueBetween is a function that gets a user's open tasks due in a period.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param from - the start of the period
+// @param to - the end of the period (exclusive)
+// @param limit - the maximum number of tasks
+// @return tasks - the tasks due first come first
+// @return error
+func GetUserTasksDueBetween(ctx context.Context, uid string, from, to time.Time, limit int) ([]Task, error) {
+	// query statement to be executed
+	query := `
+    SELECT * FROM tasks
+    WHERE uid = :uid AND NOT completed AND NOT archived AND due_at >= :from AND due_at < :to
+    ORDER BY due_at ASC, id ASC
+    LIMIT :limit
+  `
+
+	// execute query
+	tasks := []Task{}
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":   uid,
+		"from":  from.UTC(),
+		"to":    to.UTC(),
+		"limit": limit,
+	}, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting due tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetUserTasksCompletedSince - GetUserTasksCompletedSince is a function that gets a user's tasks completed after a time.
+//
+// @param ctx - context.Context
+// @param uid - string
+// @param since - time.Time
+// @param limit - the maximum number of tasks
+// @return tasks - the last completed tasks first
+// @return error
+func GetUserTasksCompletedSince(ctx context.Context, uid string, since time.Time, limit int) ([]Task, error) {
+	// query statement to be executed
+	query := `
+    SELECT * FROM tasks
+    WHERE uid = :uid AND completed AND completed_at >= :since
+    ORDER BY completed_at DESC, id DESC
+    LIMIT :limit
+  `
+
+	// execute query
+	tasks := []Task{}
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, query, map[string]any{
+		"uid":   uid,
+		"since": since.UTC(),
+		"limit": limit,
+	}, &tasks); err != nil {
+		return nil, fmt.Errorf("selecting completed tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
 // ToggleComplete - ToggleComplete is a function that toggles a task's complete status.
 //
 // @param ctx - context.Context
 // @param id - string
+// @return task - the task after the change
 // @return error
-func ToggleComplete(ctx context.Context, id string) error {
+func ToggleComplete(ctx context.Context, id string) (*Task, error) {
 	// check if task exists
 	task, err := FindOneByField(ctx, "id", "=", id)
 	if err != nil {
-		return fmt.Errorf("selecting task: %w", err)
+		return nil, fmt.Errorf("selecting task: %w", err)
 	}
 
+	task.Completed = !task.Completed
+	task.CompletedAt = time.Now().UTC()
+	task.UpdatedAt = time.Now().UTC()
+
 	// query statement to be executed
 	query := `
     UPDATE tasks 
@@ -366,18 +962,123 @@ func ToggleComplete(ctx context.Context, id string) error {
     WHERE id = :id
   `
 
+	// execute query
+	if err := database.NamedExecQuery(ctx, tasksDatabase, query, map[string]any{
+		"id":           task.ID,
+		"completed":    task.Completed,
+		"completed_at": task.CompletedAt,
+		"updated_at":   task.UpdatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("updating task: %w", err)
+	}
+
+	// return task
+	return &task, nil
+}
+
+// ToggleArchive - ToggleArchive is a function that archives or unarchives a task.
+//
+// @param ctx - context.Context
+// @param id - string
+// @return task - the task after the change
+// @return error
+func ToggleArchive(ctx context.Context, id string) (*Task, error) {
+	// check if task exists
+	task, err := FindOneByField(ctx, "id", "=", id)
+	if err != nil {
+		return nil, fmt.Errorf("selecting task: %w", err)
+	}
+
+	task.Archived = !task.Archived
+	task.ArchivedAt = time.Now().UTC()
+	task.UpdatedAt = time.Now().UTC()
+
+	// query statement to be executed
+	query := `
+    UPDATE tasks
+    SET archived = :archived, archived_at = :archived_at, updated_at = :updated_at
+    WHERE id = :id
+  `
+
 	// execute query
 	if err := database.NamedExecQuery(ctx, tasksDatabase, query, map[string]any{
 		"id":          task.ID,
-		"completed":   !task.Completed,
-		"completedAt": time.Now().UTC(),
-		"updatedAt":   time.Now().UTC(),
+		"archived":    task.Archived,
+		"archived_at": task.ArchivedAt,
+		"updated_at":  task.UpdatedAt,
 	}); err != nil {
-		return fmt.Errorf("updating task: %w", err)
+		return nil, fmt.Errorf("updating task: %w", err)
 	}
 
 	// return task
-	return nil
+	return &task, nil
+}
+
+// Snooze - Snooze is a function that hides a task from the default listings until a time.
+//
+// @param ctx - context.Context
+// @param id - string
+// @param until - the end of the snooze, nil to end it now
+// @return task - the task after the change
+// @return error
+func Snooze(ctx context.Context, id string, until *time.Time) (*Task, error) {
+	// check if task exists
+	task, err := FindOneByField(ctx, "id", "=", id)
+	if err != nil {
+		return nil, fmt.Errorf("selecting task: %w", err)
+	}
+
+	if until != nil {
+		utc := until.UTC()
+		until = &utc
+	}
+	task.SnoozedUntil = until
+	task.UpdatedAt = time.Now().UTC()
+
+	// query statement to be executed
+	query := `
+    UPDATE tasks
+    SET snoozed_until = :snoozed_until, updated_at = :updated_at
+    WHERE id = :id
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, tasksDatabase, query, map[string]any{
+		"id":            task.ID,
+		"snoozed_until": task.SnoozedUntil,
+		"updated_at":    task.UpdatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("updating task: %w", err)
+	}
+
+	// return task
+	return &task, nil
+}
+
+// Unsnooze - Unsnooze is a function that ends the snooze of the tasks snoozed until a time that has passed.
+//
+// @param ctx - context.Context
+// @param now - time.Time
+// @return tasks - the tasks after the change
+// @return error
+func Unsnooze(ctx context.Context, now time.Time) ([]Task, error) {
+	// declare tasks
+	tasks := []Task{}
+
+	// query statement to be executed
+	query := `
+    UPDATE tasks
+    SET snoozed_until = NULL, updated_at = :now
+    WHERE snoozed_until <= :now
+    RETURNING *
+  `
+
+	// execute query
+	if err := database.NamedSliceQuery(ctx, tasksDatabase, query, map[string]any{"now": now.UTC()}, &tasks); err != nil {
+		return nil, fmt.Errorf("updating tasks: %w", err)
+	}
+
+	return tasks, nil
 }
 
 // ToggleMultipleComplete - ToggleMultipleComplete is a function that toggles multiple tasks' complete status.
@@ -7,4 +7,6 @@ import (
 var (
 	// ErrNotFound - not found error
 	ErrNotFound = errors.New("task not found")
+	// ErrCategoryNotFound - the category of a task is not one of the user's
+	ErrCategoryNotFound = errors.New("category not found")
 )
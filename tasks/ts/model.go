@@ -1,24 +1,66 @@
 package ts
 
-import "time"
+import (
+	"time"
+
+	"encore.app/pkg/events"
+	"encore.app/pkg/pagination"
+)
+
+// sortableColumns - the fields tasks can be sorted by
+var sortableColumns = map[string]pagination.Column{
+	"createdAt":      {Name: "created_at", Type: "TIMESTAMP"},
+	"updatedAt":      {Name: "updated_at", Type: "TIMESTAMP"},
+	"completedAt":    {Name: "completed_at", Type: "TIMESTAMP"},
+	"title":          {Name: "title", Type: "TEXT"},
+	"pinnedPosition": {Name: "pinned_position", Type: "INTEGER"},
+}
+
+// priorities of a task
+const (
+	PriorityNone   = "none"
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// legacyCategory - the category name tasks used to default to, it means no category unless the user has one with the name
+const legacyCategory = "general"
+
+// selectableColumns - the fields clients can select
+var selectableColumns = pagination.Selectable(Task{})
 
 type Task struct {
-	ID             string    `json:"id" db:"id"`
-	UserID         string    `json:"uid" db:"uid"`
-	Title          string    `json:"title" db:"title"`
-	Description    string    `json:"description" db:"description"`
-	Status         string    `json:"status" db:"status"`     // pending, completed, archived
-	Category       string    `json:"category" db:"category"` // default: "general", "work", "personal", "shopping", "others"
-	Pinned         bool      `json:"pinned" db:"pinned"`
-	PinnedAt       time.Time `json:"pinnedAt" db:"pinned_at"`
-	PinnedPosition int       `json:"pinnedPosition" db:"pinned_position"` // default -1 -> not pinned
-	Archived       bool      `json:"archived" db:"archived"`
-	ArchivedAt     time.Time `json:"archivedAt" db:"archived_at"`
-	Completed      bool      `json:"completed" db:"completed"` // default: false
-	CompletedAt    time.Time `json:"completedAt" db:"completed_at"`
-	Color          string    `json:"color" db:"color"` // default: "default", "red", "orange", "yellow", "green", "blue", "purple", "pink", "brown", "grey"
-	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
+	ID             string     `json:"id" db:"id"`
+	UserID         string     `json:"uid" db:"uid"`
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description" db:"description"`     // markdown
+	HTML           string     `json:"descriptionHtml,omitempty" db:"-"` // sanitized html of the description, only set on single tasks
+	Status         string     `json:"status" db:"status"`               // pending, completed, archived
+	CategoryID     *string    `json:"categoryId" db:"category_id"`      // nil -> no category
+	Category       string     `json:"category" db:"category"`           // the name of the category, kept in sync with categoryId, empty -> no category
+	Pinned         bool       `json:"pinned" db:"pinned"`
+	PinnedAt       time.Time  `json:"pinnedAt" db:"pinned_at"`
+	PinnedPosition int        `json:"pinnedPosition" db:"pinned_position"` // default -1 -> not pinned
+	Archived       bool       `json:"archived" db:"archived"`
+	ArchivedAt     time.Time  `json:"archivedAt" db:"archived_at"`
+	Completed      bool       `json:"completed" db:"completed"` // default: false
+	CompletedAt    time.Time  `json:"completedAt" db:"completed_at"`
+	Color          string     `json:"color" db:"color"`                // a palette color name ("default", "red", "orange", ...) or "#rrggbb"
+	DueAt          *time.Time `json:"dueAt" db:"due_at"`               // nil -> no due date
+	Priority       string     `json:"priority" db:"priority"`          // default: "none", "low", "medium", "high"
+	Recurrence     string     `json:"recurrence" db:"recurrence"`      // RFC 5545 RRULE, e.g. "FREQ=WEEKLY;BYDAY=MO", empty -> does not repeat
+	SnoozedUntil   *time.Time `json:"snoozedUntil" db:"snoozed_until"` // hidden from the default listings until then, nil -> not snoozed
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
+
+	fieldset *pagination.Fieldset // the fields to marshal, all when nil
+}
+
+// MarshalJSON - marshals the task keeping only the requested fields
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+	return t.fieldset.Marshal(task(t))
 }
 
 type Pin struct {
@@ -41,25 +83,147 @@ type Category struct {
 
 type CreateTaskPayload struct {
 	Title       string `json:"title" db:"title"`
-	Description string `json:"description" db:"description" validate:"omitempty"`             // optional
-	Status      string `json:"status" db:"status" validate:"omitempty" default:"pending"`     // pending, completed, archived
-	Category    string `json:"category" db:"category" validate:"omitempty" default:"general"` // default: "general", "work", "personal", "shopping", "others"
+	Description string `json:"description" db:"description" validate:"omitempty"`                                     // optional
+	Status      string `json:"status" db:"status" validate:"omitempty" default:"pending"`                             // pending, completed, archived
+	CategoryID  string `json:"categoryId" db:"-" validate:"omitempty,uuid"`                                           // optional, the id of one of the user's categories
+	Category    string `json:"category" db:"-" validate:"omitempty"`                                                  // optional, the name of one of the user's categories when no id is given
+	DueAt       string `json:"dueAt" db:"due_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`             // optional, RFC 3339
+	Priority    string `json:"priority" db:"priority" validate:"omitempty,oneof=none low medium high" default:"none"` // default: "none", "low", "medium", "high"
+	Recurrence  string `json:"recurrence" db:"recurrence" validate:"omitempty"`                                       // optional, RFC 5545 RRULE
+	Color       string `json:"color" db:"color" validate:"omitempty"`                                                 // default: "default", a palette color, hex or rgb(r, g, b)
 }
 
 type UpdateTaskPayload struct {
-	Title       string `json:"title" db:"title" validate:"omitempty"`                         // optional
-	Description string `json:"description" db:"description" validate:"omitempty"`             // optional
-	Status      string `json:"status" db:"status" validate:"omitempty" default:"pending"`     // pending, completed, archived
-	Category    string `json:"category" db:"category" validate:"omitempty" default:"general"` // default: "general", "work", "personal", "shopping", "others"
+	Title       string  `json:"title" db:"title" validate:"omitempty"`                                    // optional
+	Description string  `json:"description" db:"description" validate:"omitempty"`                        // optional
+	Status      string  `json:"status" db:"status" validate:"omitempty" default:"pending"`                // pending, completed, archived
+	CategoryID  string  `json:"categoryId" db:"-" validate:"omitempty,uuid"`                              // optional, the id of one of the user's categories
+	Category    string  `json:"category" db:"-" validate:"omitempty"`                                     // optional, the name of one of the user's categories when no id is given
+	DueAt       *string `json:"dueAt" db:"-" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00|eq="` // optional, RFC 3339, empty to remove the due date
+	Priority    string  `json:"priority" db:"priority" validate:"omitempty,oneof=none low medium high"`   // optional
+	Recurrence  string  `json:"recurrence" db:"recurrence" validate:"omitempty"`                          // optional, RFC 5545 RRULE
+	Color       string  `json:"color" db:"color" validate:"omitempty"`                                    // optional, a palette color, hex or rgb(r, g, b)
+}
+
+// ListOptions - the pagination options of a task listing and the tasks it includes
+type ListOptions struct {
+	Limit          int    `json:"limit" url:"limit"`                   // the number of items
+	Page           int    `json:"page" url:"page"`                     // the page
+	Cursor         string `json:"cursor" url:"cursor"`                 // the cursor to continue from (keyset pagination)
+	Sort           string `json:"sort" url:"sort"`                     // comma separated fields to sort by
+	Order          string `json:"order" url:"order"`                   // the sort direction: asc, desc, default: "desc"
+	Fields         string `json:"fields" url:"fields"`                 // comma separated fields to return
+	IncludeSnoozed bool   `json:"includeSnoozed" url:"includeSnoozed"` // list snoozed tasks too, default: false
+}
+
+// Pagination - returns the pagination options of the listing.
+//
+// @return *pagination.Options
+func (o *ListOptions) Pagination() *pagination.Options {
+	return &pagination.Options{
+		Limit:  o.Limit,
+		Page:   o.Page,
+		Cursor: o.Cursor,
+		Sort:   o.Sort,
+		Order:  o.Order,
+		Fields: o.Fields,
+	}
 }
 
 type PaginatedTasksResponse struct {
 	Tasks       []Task `json:"data"`
-	Total       int    `json:"total" db:"total"`
-	TotalPages  int    `json:"totalPages" db:"total_pages"`
-	CurrentPage int    `json:"currentPage" db:"current_page"`
+	Total       int    `json:"total" db:"total"`              // page mode only
+	TotalPages  int    `json:"totalPages" db:"total_pages"`   // page mode only
+	CurrentPage int    `json:"currentPage" db:"current_page"` // page mode only
+	NextCursor  string `json:"nextCursor" db:"next_cursor"`   // empty when there are no more tasks
 }
 
 type MultiIdsPayload struct {
 	Ids []string `json:"ids" db:"ids"`
 }
+
+// Event - returns the task as it is published in task events.
+//
+// @return events.Task
+func (t *Task) Event() events.Task {
+	event := events.Task{
+		ID:           t.ID,
+		UserID:       t.UserID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Status:       t.Status,
+		CategoryID:   t.CategoryID,
+		Category:     t.Category,
+		Priority:     t.Priority,
+		Color:        t.Color,
+		Recurrence:   t.Recurrence,
+		Pinned:       t.Pinned,
+		Archived:     t.Archived,
+		Completed:    t.Completed,
+		DueAt:        t.DueAt,
+		SnoozedUntil: t.SnoozedUntil,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+	if t.Completed {
+		completedAt := t.CompletedAt
+		event.CompletedAt = &completedAt
+	}
+
+	return event
+}
+
+// Changes - returns the names of the fields that differ between two states of a task,
+// as the json keys of the event payload (events.Task).
+//
+// @param before - *Task
+// @param after - *Task
+// @return []string
+func Changes(before, after *Task) []string {
+	changed := []string{}
+	add := func(name string, differs bool) {
+		if differs {
+			changed = append(changed, name)
+		}
+	}
+
+	add("title", before.Title != after.Title)
+	add("description", before.Description != after.Description)
+	add("status", before.Status != after.Status)
+	add("category_id", !sameString(before.CategoryID, after.CategoryID))
+	add("category", before.Category != after.Category)
+	add("pinned", before.Pinned != after.Pinned)
+	add("archived", before.Archived != after.Archived)
+	add("completed", before.Completed != after.Completed)
+	add("color", before.Color != after.Color)
+	add("due_at", !sameTime(before.DueAt, after.DueAt))
+	add("priority", before.Priority != after.Priority)
+	add("recurrence", before.Recurrence != after.Recurrence)
+	add("snoozed_until", !sameTime(before.SnoozedUntil, after.SnoozedUntil))
+
+	return changed
+}
+
+// sameTime - reports whether two optional times are both unset or the same instant.
+//
+// @param a - *time.Time
+// @param b - *time.Time
+// @return bool
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// sameString - reports whether two optional strings are both unset or equal.
+//
+// @param a - *string
+// @param b - *string
+// @return bool
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,315 @@
+package undo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// get the service name
+var undoDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// TaskEntry - TaskEntry returns the entry of a task changed by an operation.
+//
+//	@param before - the task before the operation
+//	@param after - the task after the operation, nil when it was deleted
+//	@return Entry
+func TaskEntry(before, after *ts.Task) Entry {
+	entry := Entry{Task: before}
+	if after != nil {
+		updatedAt := after.UpdatedAt
+		entry.UpdatedAt = &updatedAt
+	}
+
+	return entry
+}
+
+// CategoryEntry - CategoryEntry returns the entry of a category changed by an operation.
+//
+//	@param before - the category before the operation
+//	@param after - the category after the operation, nil when it was deleted
+//	@return Entry
+func CategoryEntry(before, after *cs.Category) Entry {
+	entry := Entry{Category: before}
+	if after != nil {
+		updatedAt := after.UpdatedAt
+		entry.UpdatedAt = &updatedAt
+	}
+
+	return entry
+}
+
+// Record - Record saves the state of the tasks and categories before an operation of a user.
+// The returned token undoes the operation within the undo window.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param operation - string
+//	@param entries - []Entry
+//	@return *UndoResponse
+//	@return error
+func Record(ctx context.Context, uid, operation string, entries []Entry) (*UndoResponse, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, fmt.Errorf("generating undo token: %w", err)
+	}
+
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("encoding undo entries: %w", err)
+	}
+
+	now := time.Now().UTC()
+	s := snapshot{
+		Token:     token,
+		UserID:    uid,
+		Operation: operation,
+		Entries:   string(body),
+		CreatedAt: now,
+		ExpiresAt: now.Add(Window),
+	}
+
+	// snapshots past their window are of no use
+	if err := database.NamedExecQuery(ctx, undoDatabase, "DELETE FROM undo_snapshots WHERE expires_at < :now", map[string]any{"now": now}); err != nil {
+		return nil, fmt.Errorf("deleting expired undo snapshots: %w", err)
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO undo_snapshots (token, uid, operation, entries, created_at, expires_at)
+    VALUES (:token, :uid, :operation, :entries, :created_at, :expires_at)
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, undoDatabase, query, s); err != nil {
+		return nil, fmt.Errorf("inserting undo snapshot: %w", err)
+	}
+
+	return &UndoResponse{Token: token, ExpiresAt: &s.ExpiresAt}, nil
+}
+
+// Restore - Restore puts back the tasks and categories of an operation in one transaction.
+// Nothing is restored when one of them changed after the operation.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param token - string
+//	@return *RestoreResponse
+//	@return error
+func Restore(ctx context.Context, uid, token string) (*RestoreResponse, error) {
+	response := &RestoreResponse{Tasks: []ts.Task{}, Categories: []cs.Category{}}
+
+	err := database.Transaction(ctx, undoDatabase, func(tx *sqlx.Tx) error {
+		// lock the snapshot so it is only undone once
+		var s snapshot
+		if err := database.NamedStructQuery(ctx, tx, "SELECT * FROM undo_snapshots WHERE token = :token FOR UPDATE", map[string]any{"token": token}, &s); err != nil {
+			if err == database.ErrNotFound {
+				return ErrNotFound
+			}
+			return fmt.Errorf("selecting undo snapshot: %w", err)
+		}
+		if s.UserID != uid {
+			return ErrNotFound
+		}
+		if s.UndoneAt != nil {
+			return ErrAlreadyUndone
+		}
+		now := time.Now().UTC()
+		if now.After(s.ExpiresAt) {
+			return ErrExpired
+		}
+		response.Operation = s.Operation
+
+		var entries []Entry
+		if err := json.Unmarshal([]byte(s.Entries), &entries); err != nil {
+			return fmt.Errorf("decoding undo entries: %w", err)
+		}
+
+		for _, e := range entries {
+			var err error
+			if e.Task != nil {
+				err = restoreTask(ctx, tx, e, now, response)
+			} else if e.Category != nil {
+				err = restoreCategory(ctx, tx, e, now, response)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// query statement to be executed
+		query := "UPDATE undo_snapshots SET undone_at = :undone_at WHERE token = :token"
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, tx, query, map[string]any{"token": token, "undone_at": now}); err != nil {
+			return fmt.Errorf("updating undo snapshot: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// EachTask - calls fn with every restored task and its state before the undo, nil when it was deleted.
+//
+//	@param fn - func(previous, task *ts.Task)
+func (r *RestoreResponse) EachTask(fn func(previous, task *ts.Task)) {
+	for _, e := range r.restored {
+		if e.task != nil {
+			fn(e.previousTask, e.task)
+		}
+	}
+}
+
+// EachCategory - calls fn with every restored category and its state before the undo, nil when it was deleted.
+//
+//	@param fn - func(previous, category *cs.Category)
+func (r *RestoreResponse) EachCategory(fn func(previous, category *cs.Category)) {
+	for _, e := range r.restored {
+		if e.category != nil {
+			fn(e.previousCategory, e.category)
+		}
+	}
+}
+
+// restoreTask - puts back a task when it did not change after the operation.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param e - Entry
+//	@param now - time.Time
+//	@param response - *RestoreResponse
+//	@return error
+func restoreTask(ctx context.Context, tx *sqlx.Tx, e Entry, now time.Time, response *RestoreResponse) error {
+	var current ts.Task
+	exists, err := lock(ctx, tx, "tasks", e.Task.ID, &current)
+	if err != nil {
+		return err
+	}
+	if !unchanged(exists, current.UpdatedAt, e.UpdatedAt) {
+		return ErrConflict
+	}
+
+	task := *e.Task
+	task.UpdatedAt = now
+	if exists {
+		err = ts.Replace(ctx, tx, &task)
+	} else {
+		err = ts.Insert(ctx, tx, &task)
+	}
+	if err != nil {
+		return err
+	}
+
+	restored := entry{task: &task}
+	if exists {
+		restored.previousTask = &current
+	}
+	response.Tasks = append(response.Tasks, task)
+	response.restored = append(response.restored, restored)
+
+	return nil
+}
+
+// restoreCategory - puts back a category when it did not change after the operation.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param e - Entry
+//	@param now - time.Time
+//	@param response - *RestoreResponse
+//	@return error
+func restoreCategory(ctx context.Context, tx *sqlx.Tx, e Entry, now time.Time, response *RestoreResponse) error {
+	var current cs.Category
+	exists, err := lock(ctx, tx, "categories", e.Category.ID, &current)
+	if err != nil {
+		return err
+	}
+	if !unchanged(exists, current.UpdatedAt, e.UpdatedAt) {
+		return ErrConflict
+	}
+
+	category := *e.Category
+	category.UpdatedAt = now
+	if exists {
+		err = cs.Replace(ctx, tx, &category)
+	} else {
+		err = cs.Insert(ctx, tx, &category)
+	}
+	if err != nil {
+		return err
+	}
+
+	restored := entry{category: &category}
+	if exists {
+		restored.previousCategory = &current
+	}
+	response.Categories = append(response.Categories, category)
+	response.restored = append(response.restored, restored)
+
+	return nil
+}
+
+// unchanged - reports whether a row is as the operation left it.
+//
+//	@param exists - whether the row exists
+//	@param updatedAt - the time the row was last changed
+//	@param after - the time the operation changed it, nil when it deleted it
+//	@return bool
+func unchanged(exists bool, updatedAt time.Time, after *time.Time) bool {
+	if after == nil {
+		return !exists
+	}
+
+	// operations may keep nanoseconds the database does not store
+	d := updatedAt.Sub(*after)
+	return exists && d > -time.Microsecond && d < time.Microsecond
+}
+
+// lock - selects a row for update.
+//
+//	@param ctx - context.Context
+//	@param tx - *sqlx.Tx
+//	@param table - string
+//	@param id - string
+//	@param dest - the row
+//	@return bool - false when the row does not exist
+//	@return error
+func lock(ctx context.Context, tx *sqlx.Tx, table, id string, dest any) (bool, error) {
+	query := fmt.Sprintf("SELECT * FROM %v WHERE id = :id FOR UPDATE", table)
+	if err := database.NamedStructQuery(ctx, tx, query, map[string]any{"id": id}, dest); err != nil {
+		if err == database.ErrNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("selecting %v: %w", table, err)
+	}
+
+	return true, nil
+}
+
+// newToken - returns a random undo token.
+//
+//	@return string
+//	@return error
+func newToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,14 @@
+package undo
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrNotFound - the undo token does not exist or belongs to another user
+	ErrNotFound = &errs.Error{Code: errs.NotFound, Message: "undo token not found"}
+	// ErrExpired - the undo window of the operation has passed
+	ErrExpired = &errs.Error{Code: errs.FailedPrecondition, Message: "the operation can no longer be undone"}
+	// ErrAlreadyUndone - the operation was already undone
+	ErrAlreadyUndone = &errs.Error{Code: errs.FailedPrecondition, Message: "the operation was already undone"}
+	// ErrConflict - tasks or categories of the operation changed since, undoing it would overwrite the changes
+	ErrConflict = &errs.Error{Code: errs.Aborted, Message: "the operation cannot be undone, its tasks or categories changed since"}
+)
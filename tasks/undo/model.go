@@ -0,0 +1,66 @@
+package undo
+
+import (
+	"time"
+
+	"encore.app/tasks/cs"
+	"encore.app/tasks/ts"
+)
+
+// Window - how long an operation can be undone
+const Window = 5 * time.Minute
+
+// the operations that can be undone
+const (
+	OpUpdateTask          = "task.update"
+	OpDeleteTask          = "task.delete"
+	OpToggleTask          = "task.toggle"
+	OpToggleTasks         = "task.toggle_all"
+	OpArchiveTask         = "task.archive"
+	OpDeleteAllTasks      = "task.delete_all"
+	OpUpdateCategory      = "category.update"
+	OpDeleteCategory      = "category.delete"
+	OpDeleteAllCategories = "category.delete_all"
+	OpMergeCategories     = "category.merge"
+)
+
+// UndoResponse - returned by operations that can be undone
+type UndoResponse struct {
+	Token     string     `json:"undoToken"`     // empty when the operation cannot be undone
+	ExpiresAt *time.Time `json:"undoExpiresAt"` // the end of the undo window
+}
+
+// RestoreResponse - the tasks and categories an undo put back
+type RestoreResponse struct {
+	Operation  string        `json:"operation"`
+	Tasks      []ts.Task     `json:"tasks"`
+	Categories []cs.Category `json:"categories"`
+
+	restored []entry // the state before the undo, for events
+}
+
+// Entry - the state of a task or category before an operation
+type Entry struct {
+	Task      *ts.Task     `json:"task,omitempty"`
+	Category  *cs.Category `json:"category,omitempty"`
+	UpdatedAt *time.Time   `json:"updatedAt"` // the time the operation changed it, nil when it was deleted
+}
+
+// entry - a restored task or category and its state before the undo
+type entry struct {
+	task             *ts.Task
+	category         *cs.Category
+	previousTask     *ts.Task // nil when the task was deleted
+	previousCategory *cs.Category
+}
+
+// snapshot - a row of undo_snapshots
+type snapshot struct {
+	Token     string     `db:"token"`
+	UserID    string     `db:"uid"`
+	Operation string     `db:"operation"`
+	Entries   string     `db:"entries"` // JSON of []Entry
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UndoneAt  *time.Time `db:"undone_at"`
+}
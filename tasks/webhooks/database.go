@@ -0,0 +1,450 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"encore.dev/storage/sqldb"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"encore.app/pkg/database"
+	"encore.app/pkg/pagination"
+)
+
+// get the service name
+var webhooksDatabase = sqlx.NewDb(sqldb.Named("tasks").Stdlib(), "postgres")
+
+// Create - Create registers a webhook of a user, the response holds the signing secret.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param payload - *CreateWebhookPayload
+//	@return *WebhookResponse
+//	@return error
+func Create(ctx context.Context, uid string, payload *CreateWebhookPayload) (*WebhookResponse, error) {
+	if err := checkURL(ctx, payload.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("generating webhook secret: %w", err)
+	}
+
+	webhook := Webhook{
+		ID:          uuid.New().String(),
+		UserID:      uid,
+		URL:         payload.URL,
+		Description: strings.TrimSpace(payload.Description),
+		Secret:      secret,
+		Events:      strings.Join(payload.Events, ","),
+		Enabled:     true,
+		CreatedAt:   time.Now().UTC(),
+		UpdatedAt:   time.Now().UTC(),
+	}
+
+	// query statement to be executed
+	query := `
+    INSERT INTO webhooks (id, uid, url, description, secret, events, enabled, created_at, updated_at)
+    VALUES (:id, :uid, :url, :description, :secret, :events, :enabled, :created_at, :updated_at)
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, webhooksDatabase, query, webhook); err != nil {
+		return nil, fmt.Errorf("inserting webhook: %w", err)
+	}
+
+	response := webhook.Response()
+	response.Secret = secret
+	return &response, nil
+}
+
+// Get - Get returns a webhook of a user.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return *Webhook
+//	@return error
+func Get(ctx context.Context, uid, id string) (*Webhook, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrNotFound
+	}
+
+	// query statement to be executed
+	query := "SELECT * FROM webhooks WHERE id = :id AND uid = :uid"
+
+	var webhook Webhook
+	if err := database.NamedStructQuery(ctx, webhooksDatabase, query, map[string]any{"id": id, "uid": uid}, &webhook); err != nil {
+		if err == database.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("selecting webhook: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// List - List returns the webhooks of a user, the newest first.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@return *WebhooksResponse
+//	@return error
+func List(ctx context.Context, uid string) (*WebhooksResponse, error) {
+	// query statement to be executed
+	query := "SELECT * FROM webhooks WHERE uid = :uid ORDER BY created_at DESC, id DESC"
+
+	var webhooks []Webhook
+	if err := database.NamedSliceQuery(ctx, webhooksDatabase, query, map[string]any{"uid": uid}, &webhooks); err != nil {
+		return nil, fmt.Errorf("selecting webhooks: %w", err)
+	}
+
+	response := &WebhooksResponse{Webhooks: []WebhookResponse{}}
+	for i := range webhooks {
+		response.Webhooks = append(response.Webhooks, webhooks[i].Response())
+	}
+
+	return response, nil
+}
+
+// Update - Update changes a webhook of a user. Enabling a webhook resets its failures.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param payload - *UpdateWebhookPayload
+//	@return *WebhookResponse
+//	@return error
+func Update(ctx context.Context, uid, id string, payload *UpdateWebhookPayload) (*WebhookResponse, error) {
+	webhook, err := Get(ctx, uid, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload.URL) > 0 {
+		if err := checkURL(ctx, payload.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = payload.URL
+	}
+	if payload.Description != nil {
+		webhook.Description = strings.TrimSpace(*payload.Description)
+	}
+	if payload.Events != nil {
+		webhook.Events = strings.Join(payload.Events, ",")
+	}
+	if payload.Enabled != nil {
+		if *payload.Enabled && !webhook.Enabled {
+			webhook.Failures = 0
+			webhook.DisabledAt = nil
+		}
+		webhook.Enabled = *payload.Enabled
+	}
+	webhook.UpdatedAt = time.Now().UTC()
+
+	// query statement to be executed
+	query := `
+    UPDATE webhooks SET
+      url = :url, description = :description, events = :events, enabled = :enabled,
+      failures = :failures, disabled_at = :disabled_at, updated_at = :updated_at
+    WHERE id = :id AND uid = :uid
+  `
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, webhooksDatabase, query, webhook); err != nil {
+		return nil, fmt.Errorf("updating webhook: %w", err)
+	}
+
+	response := webhook.Response()
+	return &response, nil
+}
+
+// Delete - Delete removes a webhook of a user and its deliveries.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@return error
+func Delete(ctx context.Context, uid, id string) error {
+	if _, err := Get(ctx, uid, id); err != nil {
+		return err
+	}
+
+	// query statement to be executed
+	query := "DELETE FROM webhooks WHERE id = :id AND uid = :uid"
+
+	// execute query
+	if err := database.NamedExecQuery(ctx, webhooksDatabase, query, map[string]any{"id": id, "uid": uid}); err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Deliveries - Deliveries returns the delivery log of a webhook, the newest first.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param id - string
+//	@param options - *DeliveriesOptions
+//	@return *DeliveriesResponse
+//	@return error
+func Deliveries(ctx context.Context, uid, id string, options *DeliveriesOptions) (*DeliveriesResponse, error) {
+	if _, err := Get(ctx, uid, id); err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{"webhook_id": id, "status": options.Status}
+	filter := "webhook_id = :webhook_id AND (:status = '' OR status = :status)"
+
+	// count the deliveries
+	count, err := database.NamedCountQuery(ctx, webhooksDatabase, "SELECT COUNT(*) FROM webhook_deliveries WHERE "+filter, data)
+	if err != nil {
+		return nil, fmt.Errorf("counting webhook deliveries: %w", err)
+	}
+
+	limit := options.Limit
+	if limit < 1 {
+		limit = 20
+	}
+	paging := pagination.New(options.Page, limit, count)
+	if options.Page > paging.Pages() {
+		paging.SetPage(paging.Pages())
+	}
+	data["limit"] = paging.PerPage()
+	data["offset"] = paging.Offset()
+
+	// query statement to be executed
+	query := `
+    SELECT * FROM webhook_deliveries
+    WHERE ` + filter + `
+    ORDER BY created_at DESC, id DESC
+    LIMIT :limit OFFSET :offset
+  `
+
+	deliveries := []Delivery{}
+	if err := database.NamedSliceQuery(ctx, webhooksDatabase, query, data, &deliveries); err != nil {
+		return nil, fmt.Errorf("selecting webhook deliveries: %w", err)
+	}
+
+	return &DeliveriesResponse{
+		Deliveries:  deliveries,
+		Total:       paging.Total(),
+		TotalPages:  paging.Pages(),
+		CurrentPage: paging.Page(),
+	}, nil
+}
+
+// Dispatch - Dispatch logs a delivery of an event for every enabled webhook of the user subscribed to it.
+// Deliveries are keyed on the webhook and the source event, dispatching a redelivered event logs nothing new.
+// It returns the ids of the deliveries still pending, they are sent by Deliver.
+//
+//	@param ctx - context.Context
+//	@param uid - string
+//	@param eventID - the id of the source event, the same across redeliveries
+//	@param event - string
+//	@param data - the domain event
+//	@return []string
+//	@return error
+func Dispatch(ctx context.Context, uid, eventID, event string, data any) ([]string, error) {
+	// query statement to be executed
+	query := "SELECT * FROM webhooks WHERE uid = :uid AND enabled"
+
+	var webhooks []Webhook
+	if err := database.NamedSliceQuery(ctx, webhooksDatabase, query, map[string]any{"uid": uid}, &webhooks); err != nil {
+		return nil, fmt.Errorf("selecting webhooks: %w", err)
+	}
+
+	ids := []string{}
+	now := time.Now().UTC()
+	for i := range webhooks {
+		if !webhooks[i].Subscribed(event) {
+			continue
+		}
+
+		delivery := Delivery{
+			ID:        uuid.New().String(),
+			WebhookID: webhooks[i].ID,
+			EventID:   eventID,
+			Event:     event,
+			Status:    StatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+
+		body, err := json.Marshal(Payload{ID: delivery.ID, Event: event, UserID: uid, CreatedAt: now, Data: data})
+		if err != nil {
+			return nil, fmt.Errorf("encoding webhook payload: %w", err)
+		}
+		delivery.Payload = string(body)
+
+		// query statement to be executed
+		q := `
+      INSERT INTO webhook_deliveries (id, webhook_id, event_id, event, payload, status, created_at, updated_at)
+      VALUES (:id, :webhook_id, :event_id, :event, :payload, :status, :created_at, :updated_at)
+      ON CONFLICT (webhook_id, event_id) DO NOTHING
+    `
+
+		// execute query
+		if err := database.NamedExecQuery(ctx, webhooksDatabase, q, delivery); err != nil {
+			return nil, fmt.Errorf("inserting webhook delivery: %w", err)
+		}
+
+		// a redelivered event finds the delivery logged the first time,
+		// it is published again only while it has not been attempted
+		q = "SELECT * FROM webhook_deliveries WHERE webhook_id = :webhook_id AND event_id = :event_id"
+
+		var logged Delivery
+		if err := database.NamedStructQuery(ctx, webhooksDatabase, q, delivery, &logged); err != nil {
+			return nil, fmt.Errorf("selecting webhook delivery: %w", err)
+		}
+		if logged.Status == StatusPending && logged.Attempts == 0 {
+			ids = append(ids, logged.ID)
+		}
+	}
+
+	return ids, nil
+}
+
+// findDelivery - returns a delivery and its webhook.
+//
+//	@param ctx - context.Context
+//	@param id - string
+//	@return *Delivery
+//	@return *Webhook
+//	@return error
+func findDelivery(ctx context.Context, id string) (*Delivery, *Webhook, error) {
+	var delivery Delivery
+	if err := database.NamedStructQuery(ctx, webhooksDatabase, "SELECT * FROM webhook_deliveries WHERE id = :id", map[string]any{"id": id}, &delivery); err != nil {
+		if err == database.ErrNotFound {
+			return nil, nil, ErrDeliveryNotFound
+		}
+		return nil, nil, fmt.Errorf("selecting webhook delivery: %w", err)
+	}
+
+	var webhook Webhook
+	if err := database.NamedStructQuery(ctx, webhooksDatabase, "SELECT * FROM webhooks WHERE id = :id", map[string]any{"id": delivery.WebhookID}, &webhook); err != nil {
+		if err == database.ErrNotFound {
+			return nil, nil, ErrDeliveryNotFound
+		}
+		return nil, nil, fmt.Errorf("selecting webhook: %w", err)
+	}
+
+	return &delivery, &webhook, nil
+}
+
+// record - saves the result of an attempt, and the failures of the webhook.
+// A webhook failing MaxFailures times in a row, or answering 410 Gone, is disabled.
+//
+//	@param ctx - context.Context
+//	@param delivery - *Delivery
+//	@param status - the status code of the response, 0 when there was none
+//	@param sendErr - the error of the attempt, nil when it succeeded
+//	@return disabled - true when the webhook was disabled
+//	@return err - error
+func record(ctx context.Context, delivery *Delivery, status int, sendErr error) (disabled bool, err error) {
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.UpdatedAt = now
+	delivery.ResponseStatus = nil
+	if status > 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	err = database.Transaction(ctx, webhooksDatabase, func(tx *sqlx.Tx) error {
+		var q string
+		if sendErr == nil {
+			delivery.Status = StatusDelivered
+			delivery.Error = ""
+			delivery.DeliveredAt = &now
+			q = "UPDATE webhooks SET failures = 0 WHERE id = :webhook_id RETURNING enabled"
+		} else {
+			delivery.Status = StatusRetrying
+			if delivery.Attempts >= MaxAttempts {
+				delivery.Status = StatusFailed
+			}
+			delivery.Error = sendErr.Error()
+			q = `
+        UPDATE webhooks SET
+          failures = failures + 1,
+          enabled = enabled AND failures + 1 < :max_failures AND :status <> 410,
+          disabled_at = CASE WHEN enabled AND (failures + 1 >= :max_failures OR :status = 410) THEN CAST(:now AS TIMESTAMP) ELSE disabled_at END
+        WHERE id = :webhook_id
+        RETURNING enabled
+      `
+		}
+
+		var enabled []struct {
+			Enabled bool `db:"enabled"`
+		}
+		if err := database.NamedSliceQuery(ctx, tx, q, map[string]any{
+			"webhook_id":   delivery.WebhookID,
+			"max_failures": MaxFailures,
+			"status":       status,
+			"now":          now.Format(time.RFC3339Nano),
+		}, &enabled); err != nil {
+			return fmt.Errorf("updating webhook failures: %w", err)
+		}
+		disabled = len(enabled) > 0 && !enabled[0].Enabled
+
+		// a disabled webhook is not retried
+		if disabled && delivery.Status == StatusRetrying {
+			delivery.Status = StatusFailed
+		}
+
+		// query statement to be executed
+		query := `
+      UPDATE webhook_deliveries SET
+        status = :status, attempts = :attempts, response_status = :response_status, error = :error,
+        updated_at = :updated_at, delivered_at = :delivered_at
+      WHERE id = :id
+    `
+		if err := database.NamedExecQuery(ctx, tx, query, delivery); err != nil {
+			return fmt.Errorf("updating webhook delivery: %w", err)
+		}
+
+		return nil
+	})
+
+	return disabled, err
+}
+
+// skip - marks a delivery of a disabled webhook as failed without sending it.
+//
+//	@param ctx - context.Context
+//	@param delivery - *Delivery
+//	@return error
+func skip(ctx context.Context, delivery *Delivery) error {
+	delivery.Status = StatusFailed
+	delivery.Error = "webhook disabled"
+	delivery.UpdatedAt = time.Now().UTC()
+
+	// query statement to be executed
+	query := "UPDATE webhook_deliveries SET status = :status, error = :error, updated_at = :updated_at WHERE id = :id"
+
+	if err := database.NamedExecQuery(ctx, webhooksDatabase, query, delivery); err != nil {
+		return fmt.Errorf("updating webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// newSecret - returns a random signing secret.
+//
+//	@return string
+//	@return error
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,14 @@
+package webhooks
+
+import "encore.dev/beta/errs"
+
+var (
+	// ErrNotFound - the webhook does not exist or belongs to another user
+	ErrNotFound = &errs.Error{Code: errs.NotFound, Message: "webhook not found"}
+	// ErrDeliveryNotFound - the delivery does not exist, its webhook may have been deleted
+	ErrDeliveryNotFound = &errs.Error{Code: errs.NotFound, Message: "webhook delivery not found"}
+	// ErrInvalidURL - the host of the webhook url cannot be resolved
+	ErrInvalidURL = &errs.Error{Code: errs.InvalidArgument, Message: "webhook url host cannot be resolved"}
+	// ErrBlockedURL - the webhook url points at a loopback, private, link-local or unspecified address
+	ErrBlockedURL = &errs.Error{Code: errs.InvalidArgument, Message: "webhook url must point at a public address"}
+)
@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// errBlockedAddress - a delivery dialed an internal address, the host may resolve differently since it was checked
+var errBlockedAddress = errors.New("webhook url resolves to a blocked address")
+
+// blocked - reports whether an address is internal and must not be called by webhooks.
+//
+//	@param ip - net.IP
+//	@return bool
+func blocked(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
+
+// checkURL - resolves the host of a webhook url and rejects it when any of its addresses is internal.
+//
+//	@param ctx - context.Context
+//	@param rawURL - string
+//	@return error
+func checkURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || len(u.Hostname()) == 0 {
+		return ErrInvalidURL
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return ErrInvalidURL
+	}
+	for _, addr := range addrs {
+		if blocked(addr.IP) {
+			return ErrBlockedURL
+		}
+	}
+
+	return nil
+}
+
+// control - checks the resolved address right before a delivery connects,
+// so a host that was public when the webhook was saved cannot be rebound to an internal one.
+//
+//	@param network - string
+//	@param address - the resolved "ip:port"
+//	@param c - syscall.RawConn
+//	@return error
+func control(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || blocked(ip) {
+		return errBlockedAddress
+	}
+
+	return nil
+}
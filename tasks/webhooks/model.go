@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"strings"
+	"time"
+)
+
+// the events webhooks can subscribe to
+const (
+	EventTaskCreated     = "task.created"
+	EventTaskUpdated     = "task.updated"
+	EventTaskCompleted   = "task.completed"
+	EventTaskUncompleted = "task.uncompleted"
+	EventTaskArchived    = "task.archived"
+	EventTaskUnsnoozed   = "task.unsnoozed"
+	EventTaskDeleted     = "task.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+)
+
+// the statuses of a delivery
+const (
+	StatusPending   = "pending"   // not attempted yet
+	StatusRetrying  = "retrying"  // failed, it will be attempted again
+	StatusDelivered = "delivered" // the endpoint answered with a 2xx status
+	StatusFailed    = "failed"    // every attempt failed, or the webhook was disabled
+)
+
+const (
+	// MaxAttempts - the number of times a delivery is attempted, retries back off exponentially
+	MaxAttempts = 8
+	// MaxFailures - the number of failed attempts in a row after which a webhook is disabled
+	MaxFailures = 20
+)
+
+type Webhook struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"uid" db:"uid"`
+	URL         string     `json:"url" db:"url"`
+	Description string     `json:"description" db:"description"`
+	Secret      string     `json:"-" db:"secret"` // signs the payloads, only returned when the webhook is created
+	Events      string     `json:"-" db:"events"` // comma separated, empty for all events
+	Enabled     bool       `json:"enabled" db:"enabled"`
+	Failures    int        `json:"failures" db:"failures"`      // failed attempts in a row
+	DisabledAt  *time.Time `json:"disabledAt" db:"disabled_at"` // set when the webhook was disabled after failing
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+// Subscribed - reports whether the webhook receives an event.
+//
+//	@param event - string
+//	@return bool
+func (w *Webhook) Subscribed(event string) bool {
+	if len(w.Events) < 1 {
+		return true
+	}
+
+	for _, e := range strings.Split(w.Events, ",") {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Response - returns the webhook as it is shown to its owner.
+//
+//	@return WebhookResponse
+func (w *Webhook) Response() WebhookResponse {
+	events := []string{}
+	if len(w.Events) > 0 {
+		events = strings.Split(w.Events, ",")
+	}
+
+	return WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		Description: w.Description,
+		Events:      events,
+		Enabled:     w.Enabled,
+		Failures:    w.Failures,
+		DisabledAt:  w.DisabledAt,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+}
+
+type WebhookResponse struct {
+	ID          string     `json:"id"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	Events      []string   `json:"events"`           // empty for all events
+	Secret      string     `json:"secret,omitempty"` // only returned when the webhook is created
+	Enabled     bool       `json:"enabled"`
+	Failures    int        `json:"failures"`
+	DisabledAt  *time.Time `json:"disabledAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"data"`
+}
+
+type CreateWebhookPayload struct {
+	URL         string `json:"url" validate:"required,url,startswith=http"` // required
+	Description string `json:"description" validate:"max=255"`
+	// empty for all events
+	Events []string `json:"events" validate:"dive,oneof=task.created task.updated task.completed task.uncompleted task.archived task.unsnoozed task.deleted category.created category.updated category.deleted"`
+}
+
+type UpdateWebhookPayload struct {
+	URL         string  `json:"url" validate:"omitempty,url,startswith=http"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+	// unchanged when missing, empty for all events
+	Events  []string `json:"events" validate:"dive,oneof=task.created task.updated task.completed task.uncompleted task.archived task.unsnoozed task.deleted category.created category.updated category.deleted"`
+	Enabled *bool    `json:"enabled"` // enabling a webhook resets its failures
+}
+
+type Delivery struct {
+	ID             string     `json:"id" db:"id"`
+	WebhookID      string     `json:"webhookId" db:"webhook_id"`
+	EventID        string     `json:"eventId" db:"event_id"` // the source event, a webhook gets one delivery per event
+	Event          string     `json:"event" db:"event"`
+	Payload        string     `json:"payload" db:"payload"` // the signed JSON body
+	Status         string     `json:"status" db:"status"`
+	Attempts       int        `json:"attempts" db:"attempts"`
+	ResponseStatus *int       `json:"responseStatus" db:"response_status"` // the status code of the last attempt
+	Error          string     `json:"error" db:"error"`                    // the error of the last attempt
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
+	DeliveredAt    *time.Time `json:"deliveredAt" db:"delivered_at"`
+}
+
+type DeliveriesOptions struct {
+	Status string `json:"status" validate:"omitempty,oneof=pending retrying delivered failed"`
+	Page   int    `json:"page" validate:"omitempty,min=1"`
+	Limit  int    `json:"limit" validate:"omitempty,min=1,max=100"` // default: 20
+}
+
+type DeliveriesResponse struct {
+	Deliveries  []Delivery `json:"data"`
+	Total       int        `json:"total" db:"total"`
+	TotalPages  int        `json:"totalPages" db:"total_pages"`
+	CurrentPage int        `json:"currentPage" db:"current_page"`
+}
+
+// Payload - the JSON body posted to webhooks
+type Payload struct {
+	ID        string    `json:"id"` // the id of the delivery, the same for every attempt
+	Event     string    `json:"event"`
+	UserID    string    `json:"uid"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"` // the event, e.g. {"task": {...}, "changed": ["title"]}
+}
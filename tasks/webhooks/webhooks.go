@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// client - the client posting payloads, slow endpoints count as failures.
+// Every connection, redirects included, is checked against internal addresses when it is dialed.
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: control}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// Deliver - Deliver posts a logged delivery to its webhook.
+// It returns an error when the attempt failed and the delivery should be retried,
+// deliveries of deleted or disabled webhooks and deliveries out of attempts are dropped.
+//
+//	@param ctx - context.Context
+//	@param id - the id of the delivery
+//	@return error
+func Deliver(ctx context.Context, id string) error {
+	delivery, webhook, err := findDelivery(ctx, id)
+	if errors.Is(err, ErrDeliveryNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// deliveries are at least once, a redelivered message may already be done
+	if delivery.Status == StatusDelivered || delivery.Status == StatusFailed {
+		return nil
+	}
+	if !webhook.Enabled {
+		return skip(ctx, delivery)
+	}
+
+	status, sendErr := send(ctx, webhook, delivery)
+	disabled, err := record(ctx, delivery, status, sendErr)
+	if err != nil {
+		return err
+	}
+
+	if sendErr != nil && !disabled && delivery.Status == StatusRetrying {
+		return fmt.Errorf("delivering webhook %v: %w", webhook.ID, sendErr)
+	}
+
+	return nil
+}
+
+// send - posts the payload of a delivery, signed with the secret of the webhook.
+//
+//	@param ctx - context.Context
+//	@param webhook - *Webhook
+//	@param delivery - *Delivery
+//	@return int - the status code of the response, 0 when there was none
+//	@return error
+func send(ctx context.Context, webhook *Webhook, delivery *Delivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Bookie-Webhooks/1.0")
+	req.Header.Set("X-Bookie-Event", delivery.Event)
+	req.Header.Set("X-Bookie-Delivery", delivery.ID)
+	req.Header.Set("X-Bookie-Signature", fmt.Sprintf("t=%d,v1=%v", timestamp, Sign(webhook.Secret, timestamp, body)))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// drain a little of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %v", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign - Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" with the secret of a webhook.
+// Receivers recompute it from the X-Bookie-Signature timestamp (t) and compare it to v1,
+// and reject old timestamps to prevent replays.
+//
+//	@param secret - string
+//	@param timestamp - unix seconds
+//	@param body - []byte
+//	@return string
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
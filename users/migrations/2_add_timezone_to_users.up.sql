@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN timezone VARCHAR(255) NOT NULL DEFAULT 'UTC';
@@ -70,6 +70,12 @@ func Create(ctx context.Context, payload *SignupPayload) (*User, error) {
 	user.DateOfBirth = payload.DateOfBirth
 	user.Phone = strings.TrimSpace(payload.Phone)
 	user.Role = middleware.RoleSuperAdmin
+	user.Timezone = "UTC"
+
+	// set the timezone if given
+	if len(strings.TrimSpace(payload.Timezone)) > 0 {
+		user.Timezone = strings.TrimSpace(payload.Timezone)
+	}
 
 	// check if user exists with email
 	if _, err := FindOneByField(ctx, "email", "=", user.Email); err == nil {
@@ -94,10 +100,10 @@ func Create(ctx context.Context, payload *SignupPayload) (*User, error) {
 	// create query
 	query := `
     INSERT INTO users (
-      id, firstname, lastname, othernames, username, email, date_of_birth, password, phone, role, created_at, updated_at
+      id, firstname, lastname, othernames, username, email, date_of_birth, password, phone, role, timezone, created_at, updated_at
     ) 
     VALUES (
-      :id, :firstname, :lastname, :othernames, :username, :email, :date_of_birth, :password, :phone, :role, :created_at, :updated_at
+      :id, :firstname, :lastname, :othernames, :username, :email, :date_of_birth, :password, :phone, :role, :timezone, :created_at, :updated_at
     )
   `
 	// ON CONFLICT (email) DO NOTHING
@@ -149,6 +155,38 @@ func GetWithID(ctx context.Context, id string) (*User, error) {
 	return &user, nil
 }
 
+// GetWithIDFields - GetWithIDFields is a function that gets the requested fields of a user.
+//
+//	@param ctx - context.Context
+//	@param id
+//	@param fields - comma separated fields to return, all when empty
+//	@return user
+//	@return error
+func GetWithIDFields(ctx context.Context, id, fields string) (*User, error) {
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(fields, selectableColumns)
+	if err != nil {
+		return &User{}, err
+	}
+
+	// query statement to be executed
+	q := fmt.Sprintf("SELECT %v FROM users WHERE id = :id LIMIT 1", fieldset.Select("id"))
+
+	// query user from database
+	var user User
+	if err := database.NamedStructQuery(ctx, usersDatabase, q, map[string]any{"id": id}, &user); err != nil {
+		if err == database.ErrNotFound {
+			return &User{}, ErrNotFound
+		}
+		return &User{}, err
+	}
+
+	// only marshal the requested fields
+	user.fieldset = fieldset
+
+	return &user, nil
+}
+
 // Update - Update is a function that updates a user.
 //
 //	@param ctx - context.Context
@@ -231,11 +269,29 @@ func UpdateRole(ctx context.Context, id string, role string) error {
 }
 
 // GetAll - GetAll is a function that gets all users.
+// When options carry a cursor, keyset pagination is used instead of page numbers.
 //
 //	@param ctx - context.Context
 //	@return users
 //	@return error
 func GetAll(ctx context.Context, pag *pagination.Options) (*PaginatedUsersResponse, error) {
+	// validate the sort order
+	sorting, err := pag.Sorting(sortableColumns, "DESC", "createdAt")
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the requested fields
+	fieldset, err := pagination.ParseFields(pag.Fields, selectableColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	// use keyset pagination when a cursor is provided
+	if pag.UsesCursor() {
+		return getAllAfterCursor(ctx, pag, sorting, fieldset)
+	}
+
 	var users []User = []User{}
 
 	// create query
@@ -264,7 +320,7 @@ func GetAll(ctx context.Context, pag *pagination.Options) (*PaginatedUsersRespon
 	}
 
 	// query to set offset and limit
-	const query = `SELECT * FROM users LIMIT :limit OFFSET :offset`
+	query := fmt.Sprintf(`SELECT %v FROM users ORDER BY %v LIMIT :limit OFFSET :offset`, fieldset.Select(sorting.Names()...), sorting.OrderBy())
 	// data to be passed to the query
 	p := struct {
 		Limit  int `db:"limit" json:"limit" validate:"omitempty" url:"limit"`
@@ -279,6 +335,88 @@ func GetAll(ctx context.Context, pag *pagination.Options) (*PaginatedUsersRespon
 		return nil, fmt.Errorf("getting categories: %w", err)
 	}
 
+	// create a cursor from the last user so clients can switch to keyset pagination
+	nextCursor, err := pagination.NextCursor(sorting, users, paging.HasNext())
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	return &PaginatedUsersResponse{
+		TotalPages:  paging.Pages(),
+		Total:       paging.Total(),
+		CurrentPage: paging.Page(),
+		NextCursor:  nextCursor,
+		Users:       usersResponses(users, fieldset),
+	}, nil
+}
+
+// getAllAfterCursor - gets the page of users that follows the cursor.
+//
+//	@param ctx - context.Context
+//	@param pag - *pagination.Options
+//	@param sorting - *pagination.Sorting
+//	@param fieldset - *pagination.Fieldset
+//	@return users
+//	@return error
+func getAllAfterCursor(ctx context.Context, pag *pagination.Options, sorting *pagination.Sorting, fieldset *pagination.Fieldset) (*PaginatedUsersResponse, error) {
+	var users []User = []User{}
+
+	// decode and verify the cursor
+	cursor, err := pagination.DecodeCursor(pag.Cursor, sorting.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	// condition selecting the users after the cursor
+	after, args, err := sorting.After(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// set limit to the default if it is out of range
+	if pag.Limit < 1 || pag.Limit > pagination.MaxLimit {
+		pag.Limit = pagination.DefaultLimit
+	}
+
+	// query statement to be executed (one extra row tells if there is a next page)
+	query := fmt.Sprintf(`
+    SELECT %v FROM users
+    WHERE %v
+    ORDER BY %v
+    LIMIT :limit
+  `, fieldset.Select(sorting.Names()...), after, sorting.OrderBy())
+
+	args["limit"] = pag.Limit + 1
+
+	// execute query
+	if err := database.NamedSliceQuery(ctx, usersDatabase, query, args, &users); err != nil {
+		return nil, fmt.Errorf("getting users: %w", err)
+	}
+
+	// drop the extra row
+	hasNext := len(users) > pag.Limit
+	if hasNext {
+		users = users[:pag.Limit]
+	}
+
+	// create the cursor for the next page
+	nextCursor, err := pagination.NextCursor(sorting, users, hasNext)
+	if err != nil {
+		return nil, fmt.Errorf("encoding cursor: %w", err)
+	}
+
+	return &PaginatedUsersResponse{
+		NextCursor: nextCursor,
+		Users:      usersResponses(users, fieldset),
+	}, nil
+}
+
+// usersResponses - converts users to their response form (without passwords).
+//
+//	@param users - []User
+//	@param fieldset - the fields to marshal, all when nil
+//	@return []UserResponse
+func usersResponses(users []User, fieldset *pagination.Fieldset) []UserResponse {
 	// create users for response
 	usersResponse := []UserResponse{}
 
@@ -294,17 +432,14 @@ func GetAll(ctx context.Context, pag *pagination.Options) (*PaginatedUsersRespon
 			DateOfBirth: user.DateOfBirth,
 			Phone:       user.Phone,
 			Role:        user.Role,
+			Timezone:    user.Timezone,
 			CreatedAt:   user.CreatedAt,
 			UpdatedAt:   user.UpdatedAt,
+			fieldset:    fieldset,
 		})
 	}
 
-	return &PaginatedUsersResponse{
-		TotalPages:  paging.Pages(),
-		Total:       paging.Total(),
-		CurrentPage: paging.Page(),
-		Users:       usersResponse,
-	}, nil
+	return usersResponse
 }
 
 // Delete - Delete is a function that deletes a user.
@@ -2,8 +2,23 @@ package store
 
 import (
 	"time"
+
+	"encore.app/pkg/pagination"
 )
 
+// sortableColumns - the fields users can be sorted by
+var sortableColumns = map[string]pagination.Column{
+	"createdAt": {Name: "created_at", Type: "TIMESTAMP"},
+	"updatedAt": {Name: "updated_at", Type: "TIMESTAMP"},
+	"username":  {Name: "username", Type: "VARCHAR"},
+	"email":     {Name: "email", Type: "VARCHAR"},
+	"firstname": {Name: "firstname", Type: "VARCHAR"},
+	"lastname":  {Name: "lastname", Type: "VARCHAR"},
+}
+
+// selectableColumns - the fields clients can select
+var selectableColumns = pagination.Selectable(User{}, "password")
+
 type User struct {
 	ID          string    `json:"id" db:"id"`
 	Firstname   string    `json:"firstname" db:"firstname"`
@@ -15,29 +30,40 @@ type User struct {
 	Password    string    `json:"password" db:"password"`
 	Phone       string    `json:"phone" db:"phone"`
 	Role        string    `json:"role" db:"role"`
+	Timezone    string    `json:"timezone" db:"timezone"` // IANA name, default: "UTC"
 	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+
+	fieldset *pagination.Fieldset // the fields to marshal, all when nil
+}
+
+// MarshalJSON - marshals the user keeping only the requested fields
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	return u.fieldset.Marshal(user(u))
 }
 
 type SignupPayload struct {
-	Firstname   string `json:"firstname" validate:"required"`        // required
-	Lastname    string `json:"lastname" validate:"required"`         // required
-	Othernames  string `json:"othernames" validate:"omitempty"`      // optional
-	Username    string `json:"username" validate:"required"`         // required
-	DateOfBirth string `json:"dateOfBirth" validate:"omitempty"`     // optional
-	Email       string `json:"email" validate:"required,email"`      // required
-	Password    string `json:"password" validate:"required" min:"8"` // required
-	Phone       string `json:"phone" validate:"required"`            // required
+	Firstname   string `json:"firstname" validate:"required"`          // required
+	Lastname    string `json:"lastname" validate:"required"`           // required
+	Othernames  string `json:"othernames" validate:"omitempty"`        // optional
+	Username    string `json:"username" validate:"required"`           // required
+	DateOfBirth string `json:"dateOfBirth" validate:"omitempty"`       // optional
+	Email       string `json:"email" validate:"required,email"`        // required
+	Password    string `json:"password" validate:"required" min:"8"`   // required
+	Phone       string `json:"phone" validate:"required"`              // required
+	Timezone    string `json:"timezone" validate:"omitempty,timezone"` // optional, IANA name
 }
 
 type UpdatePayload struct {
-	Firstname   string `json:"firstname" db:"firstname" validate:"omitempty"`     // not required
-	Lastname    string `json:"lastname" db:"lastname" validate:"omitempty"`       // not required
-	Othernames  string `json:"othernames" db:"othernames" validate:"omitempty"`   // not required
-	Username    string `json:"username" db:"username" validate:"omitempty"`       // not required
-	DateOfBirth string `json:"dateOfBirth" db:"dateOfBirth" validate:"omitempty"` // not required
-	Email       string `json:"email" db:"email" validate:"omitempty,email"`       // not required
-	Phone       string `json:"phone" db:"phone" validate:"omitempty"`             // not required
+	Firstname   string `json:"firstname" db:"firstname" validate:"omitempty"`        // not required
+	Lastname    string `json:"lastname" db:"lastname" validate:"omitempty"`          // not required
+	Othernames  string `json:"othernames" db:"othernames" validate:"omitempty"`      // not required
+	Username    string `json:"username" db:"username" validate:"omitempty"`          // not required
+	DateOfBirth string `json:"dateOfBirth" db:"dateOfBirth" validate:"omitempty"`    // not required
+	Email       string `json:"email" db:"email" validate:"omitempty,email"`          // not required
+	Phone       string `json:"phone" db:"phone" validate:"omitempty"`                // not required
+	Timezone    string `json:"timezone" db:"timezone" validate:"omitempty,timezone"` // not required, IANA name
 }
 
 type UpdateRolePayload struct {
@@ -54,15 +80,25 @@ type UserResponse struct {
 	Email       string    `json:"email" db:"email"`
 	Phone       string    `json:"phone" db:"phone"`
 	Role        string    `json:"role" db:"role"`
+	Timezone    string    `json:"timezone" db:"timezone"`
 	CreatedAt   time.Time `json:"createdAt" db:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt" db:"updatedAt"`
+
+	fieldset *pagination.Fieldset // the fields to marshal, all when nil
+}
+
+// MarshalJSON - marshals the user keeping only the requested fields
+func (u UserResponse) MarshalJSON() ([]byte, error) {
+	type userResponse UserResponse
+	return u.fieldset.Marshal(userResponse(u))
 }
 
 type PaginatedUsersResponse struct {
 	Users       []UserResponse `json:"data"`
-	Total       int            `json:"total" db:"total"`
-	TotalPages  int            `json:"totalPages" db:"totalPages"`
-	CurrentPage int            `json:"currentPage" db:"currentPage"`
+	Total       int            `json:"total" db:"total"`             // page mode only
+	TotalPages  int            `json:"totalPages" db:"totalPages"`   // page mode only
+	CurrentPage int            `json:"currentPage" db:"currentPage"` // page mode only
+	NextCursor  string         `json:"nextCursor" db:"nextCursor"`   // empty when there are no more users
 }
 
 type UserUpdateResponse struct {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"encore.dev/rlog"
 	"github.com/go-playground/validator/v10"
 
 	"encore.app/pkg/events"
@@ -27,6 +28,14 @@ func Create(ctx context.Context, payload *store.SignupPayload) (*store.User, err
 		return nil, err
 	}
 
+	// publish the user created event, the user is saved even when it fails
+	if _, err := events.UserCreated.Publish(ctx, &events.UserCreatedEvent{
+		UserID:    user.ID,
+		CreatedAt: user.CreatedAt,
+	}); err != nil {
+		rlog.Error("publishing event failed", "topic", "user-created", "err", err)
+	}
+
 	return user, nil
 }
 
@@ -63,11 +72,12 @@ func QueryAll(ctx context.Context, options *pagination.Options) (*store.Paginate
 //
 //	@param ctx - context.Context
 //	@param id
+//	@param query - *pagination.Query
 //	@return user
 //	@return error
 //
 // encore:api auth method=GET path=/users/:id
-func Get(ctx context.Context, id string) (*store.User, error) {
+func Get(ctx context.Context, id string, query *pagination.Query) (*store.User, error) {
 	// check for claims
 	claims, err := middleware.GetVerifiedClaims(ctx, "")
 	if err != nil {
@@ -80,7 +90,7 @@ func Get(ctx context.Context, id string) (*store.User, error) {
 	}
 
 	// get user
-	user, err := store.GetWithID(ctx, id)
+	user, err := store.GetWithIDFields(ctx, id, query.Fields)
 	if err != nil {
 		return nil, err
 	}
@@ -188,3 +198,29 @@ func UpdateRole(ctx context.Context, id string) error {
 	// return user
 	return nil
 }
+
+type Contact struct {
+	ID        string `json:"id"`
+	Firstname string `json:"firstname"`
+	Email     string `json:"email"`
+	Timezone  string `json:"timezone"` // IANA name, default: "UTC"
+}
+
+// GetContact - Get the name, email and timezone of a user, for the services messaging users
+//
+//	@param ctx - context.Context
+//	@param id
+//	@return contact
+//	@return error
+//
+// encore:api private method=GET path=/internal/users/:id/contact
+func GetContact(ctx context.Context, id string) (*Contact, error) {
+	// get user
+	user, err := store.GetWithID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// return contact
+	return &Contact{ID: user.ID, Firstname: user.Firstname, Email: user.Email, Timezone: user.Timezone}, nil
+}